@@ -0,0 +1,342 @@
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// IssueSeverity grades a finding reported by one of the Book checkers.
+type IssueSeverity string
+
+// Issue severities.
+const (
+	// SeverityError marks a violation of a requirement.
+	SeverityError IssueSeverity = "error"
+	// SeverityWarning marks a deviation from a recommendation.
+	SeverityWarning IssueSeverity = "warning"
+)
+
+// Accessibility report categories, following the structure of EPUB
+// Accessibility 1.1.
+const (
+	A11yCategoryDiscovery      = "discovery"
+	A11yCategoryConformance    = "conformance"
+	A11yCategoryPageNavigation = "page-navigation"
+	A11yCategoryWCAG           = "wcag"
+)
+
+// AccessibilityIssue is a single finding of CheckAccessibility.
+type AccessibilityIssue struct {
+	// Category is one of the A11yCategory* constants.
+	Category string
+
+	// Rule is a short machine-readable rule name (e.g., "img-alt").
+	Rule string
+
+	// Severity grades the finding.
+	Severity IssueSeverity
+
+	// Href is the ZIP-internal path of the content document the finding
+	// relates to, or empty for publication-level findings.
+	Href string
+
+	// Message is a human-readable description of the finding.
+	Message string
+}
+
+// AccessibilityReport summarises the declared accessibility metadata and the
+// results of inspecting the publication content.
+type AccessibilityReport struct {
+	// Metadata is the accessibility metadata declared in the package document.
+	Metadata Accessibility
+
+	// ConformanceLevel is the declared WCAG level ("A", "AA", "AAA"), or
+	// empty if no recognised conformance statement is present.
+	ConformanceLevel string
+
+	// Documents is the number of spine content documents inspected.
+	Documents int
+
+	// Images is the number of <img> elements found.
+	Images int
+
+	// ImagesMissingAlt is the number of <img> elements without an alt
+	// attribute. An empty alt (decorative image) is not counted.
+	ImagesMissingAlt int
+
+	// HasPageList reports whether a page-list navigation is present.
+	HasPageList bool
+
+	// Issues lists every finding in report order.
+	Issues []AccessibilityIssue
+}
+
+// Passed reports whether the report contains no error-severity issues.
+func (r AccessibilityReport) Passed() bool {
+	for _, is := range r.Issues {
+		if is.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// extractAccessibility collects schema.org accessibility and conformance
+// metadata from both the ePub 3 property form and the ePub 2 name/content form.
+func extractAccessibility(om *opfMetadata) Accessibility {
+	var a Accessibility
+	for _, m := range om.Metas {
+		if m.Refines != "" {
+			continue
+		}
+		key, val := metaKeyValue(m)
+		if val == "" {
+			continue
+		}
+		switch key {
+		case "schema:accessMode":
+			a.AccessModes = appendCommaList(a.AccessModes, val)
+		case "schema:accessModeSufficient":
+			if set := appendCommaList(nil, val); len(set) > 0 {
+				a.AccessModesSufficient = append(a.AccessModesSufficient, set)
+			}
+		case "schema:accessibilityFeature":
+			a.Features = appendCommaList(a.Features, val)
+		case "schema:accessibilityHazard":
+			a.Hazards = appendCommaList(a.Hazards, val)
+		case "schema:accessibilitySummary":
+			if a.Summary == "" {
+				a.Summary = val
+			}
+		case "dcterms:conformsTo":
+			a.ConformsTo = append(a.ConformsTo, val)
+		case "a11y:certifiedBy":
+			if a.CertifiedBy == "" {
+				a.CertifiedBy = val
+			}
+		case "a11y:certifierCredential":
+			if a.CertifierCredential == "" {
+				a.CertifierCredential = val
+			}
+		case "a11y:certifierReport":
+			if a.CertifierReport == "" {
+				a.CertifierReport = val
+			}
+		}
+	}
+
+	for _, l := range om.Links {
+		href := strings.TrimSpace(l.Href)
+		if href == "" || l.Refines != "" {
+			continue
+		}
+		for _, rel := range strings.Fields(l.Rel) {
+			switch rel {
+			case "dcterms:conformsTo":
+				a.ConformsTo = append(a.ConformsTo, href)
+			case "a11y:certifierReport":
+				if a.CertifierReport == "" {
+					a.CertifierReport = href
+				}
+			}
+		}
+	}
+	return a
+}
+
+// appendCommaList appends the non-empty, comma-separated values of s to list.
+func appendCommaList(list []string, s string) []string {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// wcagLevelPattern extracts the WCAG level from EPUB Accessibility 1.1
+// conformance strings ("... WCAG 2.1 Level AA") and from the EPUB
+// Accessibility 1.0 IDPF URLs ("...accessibility-20170105.html#wcag-aa").
+var wcagLevelPattern = regexp.MustCompile(`(?i)(?:level\s+|#wcag-)(a{1,3})\b`)
+
+// ConformanceLevel returns the highest WCAG level ("A", "AA", or "AAA")
+// claimed by a recognised dcterms:conformsTo statement, or "" if none.
+func (a Accessibility) ConformanceLevel() string {
+	best := ""
+	for _, c := range a.ConformsTo {
+		if m := wcagLevelPattern.FindStringSubmatch(c); m != nil {
+			if level := strings.ToUpper(m[1]); len(level) > len(best) {
+				best = level
+			}
+		}
+	}
+	return best
+}
+
+// CheckAccessibility inspects the package metadata and every spine content
+// document, and produces a report structured after EPUB Accessibility 1.1:
+// discovery metadata, conformance statement, page navigation, and a subset of
+// WCAG checks (image alt text, document language, and heading order).
+// Content documents that cannot be read are reported as issues.
+func (b *Book) CheckAccessibility() AccessibilityReport {
+	r := AccessibilityReport{
+		Metadata:         copyAccessibility(b.metadata.Accessibility),
+		ConformanceLevel: b.metadata.Accessibility.ConformanceLevel(),
+	}
+
+	checkA11yDiscovery(&r)
+
+	if r.ConformanceLevel == "" {
+		r.add(A11yCategoryConformance, "conformance", SeverityError, "",
+			"no recognised dcterms:conformsTo statement (e.g., \"EPUB Accessibility 1.1 - WCAG 2.1 Level AA\")")
+	}
+	if r.Metadata.CertifiedBy == "" && r.ConformanceLevel != "" {
+		r.add(A11yCategoryConformance, "certified-by", SeverityWarning, "",
+			"conformance is claimed but a11y:certifiedBy is missing")
+	}
+
+	r.HasPageList = b.hasPageList()
+	if !r.HasPageList {
+		severity := SeverityWarning
+		msg := "no page-list navigation"
+		if b.metadata.Source != "" {
+			severity = SeverityError
+			msg = "no page-list navigation although dc:source names a print source"
+		}
+		r.add(A11yCategoryPageNavigation, "page-list", severity, "", msg)
+	}
+
+	if len(b.metadata.Language) == 0 {
+		r.add(A11yCategoryWCAG, "package-lang", SeverityError, "", "no dc:language declared")
+	}
+
+	for _, ch := range b.Chapters() {
+		raw, err := ch.RawContent()
+		if err != nil {
+			r.add(A11yCategoryWCAG, "unreadable", SeverityError, ch.Href,
+				fmt.Sprintf("cannot read content document: %v", err))
+			continue
+		}
+		r.Documents++
+		checkA11yDocument(&r, raw, ch.Href)
+	}
+
+	return r
+}
+
+// checkA11yDiscovery verifies that the discovery metadata required by
+// EPUB Accessibility 1.1 is present.
+func checkA11yDiscovery(r *AccessibilityReport) {
+	md := r.Metadata
+	required := []struct {
+		rule    string
+		missing bool
+	}{
+		{"schema:accessMode", len(md.AccessModes) == 0},
+		{"schema:accessibilityFeature", len(md.Features) == 0},
+		{"schema:accessibilityHazard", len(md.Hazards) == 0},
+		{"schema:accessibilitySummary", md.Summary == ""},
+	}
+	for _, req := range required {
+		if req.missing {
+			r.add(A11yCategoryDiscovery, req.rule, SeverityError, "", req.rule+" metadata is missing")
+		}
+	}
+	if len(md.AccessModesSufficient) == 0 {
+		r.add(A11yCategoryDiscovery, "schema:accessModeSufficient", SeverityWarning, "",
+			"schema:accessModeSufficient metadata is missing")
+	}
+}
+
+// checkA11yDocument inspects a single content document for alt text,
+// language declaration, and heading order.
+func checkA11yDocument(r *AccessibilityReport, data []byte, href string) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		r.add(A11yCategoryWCAG, "unparsable", SeverityError, href, fmt.Sprintf("cannot parse content document: %v", err))
+		return
+	}
+
+	if root := findElement(doc, atom.Html); root == nil || (navGetAttr(root, "lang") == "" && navGetAttr(root, "xml:lang") == "") {
+		r.add(A11yCategoryWCAG, "html-lang", SeverityError, href, "<html> element has no lang or xml:lang attribute")
+	}
+
+	prevLevel := 0
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Img:
+				r.Images++
+				if !hasAttr(n, "alt") {
+					r.ImagesMissingAlt++
+					r.add(A11yCategoryWCAG, "img-alt", SeverityError, href,
+						fmt.Sprintf("<img src=%q> has no alt attribute", navGetAttr(n, "src")))
+				}
+			case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				level := headingLevel(n.DataAtom)
+				if prevLevel > 0 && level > prevLevel+1 {
+					r.add(A11yCategoryWCAG, "heading-order", SeverityWarning, href,
+						fmt.Sprintf("heading level skips from h%d to h%d", prevLevel, level))
+				}
+				prevLevel = level
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+}
+
+// add appends an issue to the report.
+func (r *AccessibilityReport) add(category, rule string, severity IssueSeverity, href, msg string) {
+	r.Issues = append(r.Issues, AccessibilityIssue{
+		Category: category,
+		Rule:     rule,
+		Severity: severity,
+		Href:     href,
+		Message:  msg,
+	})
+}
+
+// hasAttr reports whether n has an attribute with the given key.
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// headingLevel returns 1–6 for <h1>–<h6>, or 0 for any other element.
+func headingLevel(a atom.Atom) int {
+	switch a {
+	case atom.H1:
+		return 1
+	case atom.H2:
+		return 2
+	case atom.H3:
+		return 3
+	case atom.H4:
+		return 4
+	case atom.H5:
+		return 5
+	case atom.H6:
+		return 6
+	}
+	return 0
+}
+
+// hasPageList reports whether the nav document contains a page-list nav or
+// the NCX contains a <pageList>. It uses the cached PageList result.
+func (b *Book) hasPageList() bool {
+	b.buildPageList()
+	return b.pagesDeclared
+}
@@ -0,0 +1,281 @@
+package epub
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const a11yTestOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:a11y</dc:identifier>
+    <dc:title>Accessible Book</dc:title>
+    <dc:language>en</dc:language>
+    <meta property="schema:accessMode">textual</meta>
+    <meta property="schema:accessMode">visual</meta>
+    <meta property="schema:accessModeSufficient">textual</meta>
+    <meta property="schema:accessModeSufficient">textual, visual</meta>
+    <meta property="schema:accessibilityFeature">alternativeText</meta>
+    <meta property="schema:accessibilityFeature">structuralNavigation</meta>
+    <meta property="schema:accessibilityHazard">none</meta>
+    <meta property="schema:accessibilitySummary">All images have alt text.</meta>
+    <meta property="dcterms:conformsTo">EPUB Accessibility 1.1 - WCAG 2.1 Level AA</meta>
+    <meta property="a11y:certifiedBy">Example Certifier</meta>
+    <link rel="a11y:certifierReport" href="https://example.com/report.html"/>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+  </spine>
+</package>`
+
+const a11yTestNav = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<body>
+<nav epub:type="toc"><ol><li><a href="ch1.xhtml">One</a></li></ol></nav>
+<nav epub:type="page-list" hidden=""><ol><li><a href="ch1.xhtml#p1">1</a></li></ol></nav>
+</body>
+</html>`
+
+func TestExtractAccessibility(t *testing.T) {
+	pkg, err := parseOPF([]byte(a11yTestOPF))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	a := extractMetadata(pkg).Accessibility
+
+	want := Accessibility{
+		AccessModes:           []string{"textual", "visual"},
+		AccessModesSufficient: [][]string{{"textual"}, {"textual", "visual"}},
+		Features:              []string{"alternativeText", "structuralNavigation"},
+		Hazards:               []string{"none"},
+		Summary:               "All images have alt text.",
+		ConformsTo:            []string{"EPUB Accessibility 1.1 - WCAG 2.1 Level AA"},
+		CertifiedBy:           "Example Certifier",
+		CertifierReport:       "https://example.com/report.html",
+	}
+	if !reflect.DeepEqual(a, want) {
+		t.Errorf("Accessibility = %+v, want %+v", a, want)
+	}
+}
+
+func TestExtractAccessibility_EPub2Form(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <meta name="schema:accessMode" content="textual"/>
+    <meta name="schema:accessibilityHazard" content="noFlashingHazard, noSoundHazard"/>
+    <meta name="dcterms:conformsTo" content="http://www.idpf.org/epub/a11y/accessibility-20170105.html#wcag-a"/>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+	pkg, err := parseOPF([]byte(opf))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	a := extractMetadata(pkg).Accessibility
+
+	if !reflect.DeepEqual(a.AccessModes, []string{"textual"}) {
+		t.Errorf("AccessModes = %v, want [textual]", a.AccessModes)
+	}
+	if !reflect.DeepEqual(a.Hazards, []string{"noFlashingHazard", "noSoundHazard"}) {
+		t.Errorf("Hazards = %v, want [noFlashingHazard noSoundHazard]", a.Hazards)
+	}
+	if got := a.ConformanceLevel(); got != "A" {
+		t.Errorf("ConformanceLevel() = %q, want %q", got, "A")
+	}
+}
+
+func TestAccessibility_ConformanceLevel(t *testing.T) {
+	tests := []struct {
+		conformsTo []string
+		want       string
+	}{
+		{nil, ""},
+		{[]string{"EPUB Accessibility 1.1 - WCAG 2.2 Level AAA"}, "AAA"},
+		{[]string{"http://www.idpf.org/epub/a11y/accessibility-20170105.html#wcag-aa"}, "AA"},
+		{[]string{"EPUB Accessibility 1.1 - WCAG 2.0 Level A", "EPUB Accessibility 1.1 - WCAG 2.1 Level AA"}, "AA"},
+		{[]string{"some other profile"}, ""},
+	}
+	for _, tt := range tests {
+		a := Accessibility{ConformsTo: tt.conformsTo}
+		if got := a.ConformanceLevel(); got != tt.want {
+			t.Errorf("ConformanceLevel(%v) = %q, want %q", tt.conformsTo, got, tt.want)
+		}
+	}
+}
+
+func a11yIssueRules(r AccessibilityReport) map[string]IssueSeverity {
+	m := make(map[string]IssueSeverity, len(r.Issues))
+	for _, is := range r.Issues {
+		m[is.Rule] = is.Severity
+	}
+	return m
+}
+
+func TestCheckAccessibility_Compliant(t *testing.T) {
+	files := map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": validContainerXML,
+		"OEBPS/content.opf":      a11yTestOPF,
+		"OEBPS/nav.xhtml":        a11yTestNav,
+		"OEBPS/ch1.xhtml": `<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en"><body>
+<h1>One</h1><h2>Part</h2><img src="a.png" alt="A picture"/><img src="deco.png" alt=""/>
+</body></html>`,
+	}
+	data := buildTestEPubBytes(t, files)
+	book, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	defer book.Close()
+
+	r := book.CheckAccessibility()
+
+	if !r.Passed() {
+		t.Errorf("Passed() = false, issues: %+v", r.Issues)
+	}
+	if len(r.Issues) != 0 {
+		t.Errorf("Issues = %+v, want none", r.Issues)
+	}
+	if r.ConformanceLevel != "AA" || !r.HasPageList {
+		t.Errorf("ConformanceLevel = %q, HasPageList = %v; want AA, true", r.ConformanceLevel, r.HasPageList)
+	}
+	if r.Documents != 1 || r.Images != 2 || r.ImagesMissingAlt != 0 {
+		t.Errorf("Documents/Images/MissingAlt = %d/%d/%d, want 1/2/0", r.Documents, r.Images, r.ImagesMissingAlt)
+	}
+}
+
+func TestCheckAccessibility_Failures(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Inaccessible Book</dc:title>
+    <dc:source>urn:isbn:9780000000002</dc:source>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="missing.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+  </spine>
+</package>`
+	files := map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": validContainerXML,
+		"OEBPS/content.opf":      opf,
+		"OEBPS/ch1.xhtml": `<html><body>
+<h1>One</h1><h3>Skipped</h3><img src="a.png"/>
+</body></html>`,
+	}
+	data := buildTestEPubBytes(t, files)
+	book, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	defer book.Close()
+
+	r := book.CheckAccessibility()
+
+	if r.Passed() {
+		t.Fatal("Passed() = true, want false")
+	}
+	want := map[string]IssueSeverity{
+		"schema:accessMode":           SeverityError,
+		"schema:accessibilityFeature": SeverityError,
+		"schema:accessibilityHazard":  SeverityError,
+		"schema:accessibilitySummary": SeverityError,
+		"schema:accessModeSufficient": SeverityWarning,
+		"conformance":                 SeverityError,
+		"page-list":                   SeverityError,
+		"package-lang":                SeverityError,
+		"html-lang":                   SeverityError,
+		"img-alt":                     SeverityError,
+		"heading-order":               SeverityWarning,
+		"unreadable":                  SeverityError,
+	}
+	if got := a11yIssueRules(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("issue rules = %v, want %v", got, want)
+	}
+	if r.ImagesMissingAlt != 1 || r.Documents != 1 {
+		t.Errorf("ImagesMissingAlt = %d, Documents = %d; want 1, 1", r.ImagesMissingAlt, r.Documents)
+	}
+	for _, is := range r.Issues {
+		if is.Rule == "img-alt" && is.Href != "OEBPS/ch1.xhtml" {
+			t.Errorf("img-alt Href = %q, want %q", is.Href, "OEBPS/ch1.xhtml")
+		}
+	}
+}
+
+func TestCheckAccessibility_NCXPageList(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:language>en</dc:language></metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+  </manifest>
+  <spine toc="ncx"/>
+</package>`
+	files := map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": validContainerXML,
+		"OEBPS/content.opf":      opf,
+		"OEBPS/toc.ncx": `<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/"><navMap/>
+<pageList><pageTarget type="normal" value="1"><navLabel><text>1</text></navLabel><content src="ch1.xhtml#p1"/></pageTarget></pageList></ncx>`,
+	}
+	data := buildTestEPubBytes(t, files)
+	book, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	defer book.Close()
+
+	if r := book.CheckAccessibility(); !r.HasPageList {
+		t.Error("HasPageList = false, want true for NCX <pageList>")
+	}
+}
+
+func TestCheckAccessibility_NoDuplicateWarnings(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:language>en</dc:language></metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+  </manifest>
+  <spine toc="ncx"/>
+</package>`
+	files := map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": validContainerXML,
+		"OEBPS/content.opf":      opf,
+		"OEBPS/toc.ncx":          `<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/"><navMap><pageList>`,
+	}
+	data := buildTestEPubBytes(t, files)
+	book, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	defer book.Close()
+
+	book.CheckAccessibility()
+	book.CheckAccessibility()
+	n := 0
+	for _, w := range book.Warnings() {
+		if strings.Contains(w, "failed to parse NCX page list") {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("page list warnings = %d, want 1: %v", n, book.Warnings())
+	}
+}
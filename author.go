@@ -0,0 +1,151 @@
+package epub
+
+import (
+	"strings"
+	"unicode"
+)
+
+// nameParticles lists lower-case surname particles that are moved behind the
+// given names when generating a sort name ("Ludwig van Beethoven" →
+// "Beethoven, Ludwig van").
+var nameParticles = map[string]bool{
+	"af": true, "al": true, "av": true, "bin": true, "da": true, "das": true,
+	"de": true, "degli": true, "dei": true, "del": true, "della": true,
+	"den": true, "der": true, "des": true, "di": true, "do": true, "dos": true,
+	"du": true, "el": true, "ibn": true, "la": true, "le": true, "lo": true,
+	"ten": true, "ter": true, "van": true, "von": true, "y": true, "zu": true,
+}
+
+// nameSuffixes lists generational and academic suffixes (lower case, without
+// trailing period) that follow the given names in a sort name.
+var nameSuffixes = map[string]bool{
+	"jr": true, "sr": true, "ii": true, "iii": true, "iv": true,
+	"phd": true, "ph.d": true, "md": true, "m.d": true, "esq": true,
+}
+
+// SortName returns the name to sort this author by: FileAs when present,
+// otherwise a sort name generated from Name by GenerateSortName.
+func (a Author) SortName() string {
+	if fa := strings.TrimSpace(a.FileAs); fa != "" {
+		return fa
+	}
+	return GenerateSortName(a.Name)
+}
+
+// GenerateSortName derives a "Last, First" sort name from a display name.
+//
+//   - Names that are already inverted ("Dickens, Charles") are kept.
+//   - Surname particles follow the given names: "Vincent van Gogh" →
+//     "Gogh, Vincent van".
+//   - Suffixes are appended last: "Martin Luther King, Jr." →
+//     "King, Martin Luther, Jr.".
+//   - Names written in CJK scripts already put the family name first and are
+//     returned unchanged.
+//   - Single-word names are returned unchanged.
+func GenerateSortName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || isCJKName(name) {
+		return name
+	}
+
+	var suffixes []string
+	if strings.Contains(name, ",") {
+		parts := strings.Split(name, ",")
+		for _, p := range parts[1:] {
+			if !isNameSuffix(strings.TrimSpace(p)) {
+				// Already in "Last, First" form.
+				return name
+			}
+			suffixes = append(suffixes, strings.TrimSpace(p))
+		}
+		name = strings.TrimSpace(parts[0])
+	}
+
+	tokens := strings.Fields(name)
+	for len(tokens) > 1 && isNameSuffix(tokens[len(tokens)-1]) {
+		suffixes = append([]string{tokens[len(tokens)-1]}, suffixes...)
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) < 2 {
+		return name
+	}
+
+	// Walk back from the surname over particles, keeping at least one
+	// given name in front.
+	surnameStart := len(tokens) - 1
+	for surnameStart > 1 && nameParticles[strings.ToLower(tokens[surnameStart-1])] {
+		surnameStart--
+	}
+
+	given := tokens[:surnameStart]
+	particles := tokens[surnameStart : len(tokens)-1]
+	surname := tokens[len(tokens)-1]
+
+	parts := []string{surname, strings.Join(append(append([]string(nil), given...), particles...), " ")}
+	parts = append(parts, suffixes...)
+	return strings.Join(parts, ", ")
+}
+
+// isNameSuffix reports whether s is a generational or academic suffix.
+func isNameSuffix(s string) bool {
+	return nameSuffixes[strings.TrimSuffix(strings.ToLower(s), ".")]
+}
+
+// isCJKName reports whether name contains Han, Hiragana, Katakana, or Hangul
+// characters, i.e. is written in a script that orders family name first.
+func isCJKName(name string) bool {
+	for _, r := range name {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeAuthors merges creators that appear more than once, as happens when
+// an OPF declares the same person with ePub 2 attributes and again with
+// ePub 3 refines. Entries match when their names are equal (ignoring case
+// and whitespace) and their roles are equal or one of them is empty. The
+// first occurrence keeps its position; missing fields are filled from later
+// duplicates.
+func dedupeAuthors(authors []Author) []Author {
+	if len(authors) < 2 {
+		return authors
+	}
+
+	out := make([]Author, 0, len(authors))
+	for _, a := range authors {
+		key := strings.ToLower(strings.Join(strings.Fields(a.Name), " "))
+		merged := false
+		for i := range out {
+			e := &out[i]
+			if strings.ToLower(strings.Join(strings.Fields(e.Name), " ")) != key {
+				continue
+			}
+			if e.Role != "" && a.Role != "" && e.Role != a.Role {
+				continue
+			}
+			if e.Role == "" {
+				e.Role = a.Role
+			}
+			if e.FileAs == "" {
+				e.FileAs = a.FileAs
+			}
+			if e.Language == "" {
+				e.Language = a.Language
+			}
+			if e.Dir == "" {
+				e.Dir = a.Dir
+			}
+			if len(e.AlternateScripts) == 0 {
+				e.AlternateScripts = a.AlternateScripts
+			}
+			merged = true
+			break
+		}
+		if !merged {
+			out = append(out, a)
+		}
+	}
+	return out
+}
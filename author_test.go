@@ -0,0 +1,76 @@
+package epub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerateSortName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"Homer", "Homer"},
+		{"Charles Dickens", "Dickens, Charles"},
+		{"  Charles   John  Huffam Dickens ", "Dickens, Charles John Huffam"},
+		{"Dickens, Charles", "Dickens, Charles"},
+		{"Ludwig van Beethoven", "Beethoven, Ludwig van"},
+		{"Charles de Gaulle", "Gaulle, Charles de"},
+		{"Miguel de la Cruz", "Cruz, Miguel de la"},
+		{"Van Morrison", "Morrison, Van"},
+		{"Martin Luther King, Jr.", "King, Martin Luther, Jr."},
+		{"Martin Luther King Jr.", "King, Martin Luther, Jr."},
+		{"John Smith III", "Smith, John, III"},
+		{"Henry IV", "Henry IV"},
+		{"村上 春樹", "村上 春樹"},
+		{"김영하", "김영하"},
+	}
+	for _, tt := range tests {
+		if got := GenerateSortName(tt.in); got != tt.want {
+			t.Errorf("GenerateSortName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAuthor_SortName(t *testing.T) {
+	a := Author{Name: "Jane Austen"}
+	if got := a.SortName(); got != "Austen, Jane" {
+		t.Errorf("SortName() = %q, want %q", got, "Austen, Jane")
+	}
+	a.FileAs = "Austen, J."
+	if got := a.SortName(); got != "Austen, J." {
+		t.Errorf("SortName() with FileAs = %q, want %q", got, "Austen, J.")
+	}
+}
+
+func TestExtractMetadata_DedupesCreators(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+    <dc:creator opf:role="aut">Jane  Austen</dc:creator>
+    <dc:creator id="c1">jane austen</dc:creator>
+    <meta refines="#c1" property="role" scheme="marc:relators">aut</meta>
+    <meta refines="#c1" property="file-as">Austen, Jane</meta>
+    <dc:creator opf:role="Editor">Jane Austen</dc:creator>
+    <dc:creator opf:role="trl">Someone Else</dc:creator>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+	pkg, err := parseOPF([]byte(opf))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	md := extractMetadata(pkg)
+
+	want := []Author{
+		{Name: "Jane  Austen", FileAs: "Austen, Jane", Role: RoleAuthor},
+		{Name: "Jane Austen", Role: RoleEditor},
+		{Name: "Someone Else", Role: RoleTranslator},
+	}
+	if !reflect.DeepEqual(md.Authors, want) {
+		t.Errorf("Authors = %+v, want %+v", md.Authors, want)
+	}
+}
@@ -0,0 +1,372 @@
+package epub
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Blocks parses this chapter's XHTML into a tree of typed blocks: headings,
+// paragraphs, lists and list items, block quotes, preformatted code, tables
+// with rows and cells, figures with captions, images, sections, and rules.
+// <div> elements that contain other blocks are transparent; their children
+// are returned in their place. Script and style content is skipped, and
+// image paths are resolved to ZIP-internal paths as in BodyHTML.
+func (c Chapter) Blocks() ([]Block, error) {
+	data, err := c.RawContent()
+	if err != nil {
+		return nil, err
+	}
+	data = rewriteImagePaths(normalizeSelfClosingSkipTags(data), c.Href)
+	return parseBlocks(data)
+}
+
+// parseBlocks parses an XHTML document into blocks.
+func parseBlocks(data []byte) ([]Block, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	body := findElement(doc, atom.Body)
+	if body == nil {
+		return nil, nil
+	}
+	return parseBlockChildren(body, 0), nil
+}
+
+// parseBlockChildren converts the children of n into blocks. Consecutive
+// inline nodes are grouped into an anonymous paragraph. listDepth is the
+// nesting depth of the enclosing list.
+func parseBlockChildren(n *html.Node, listDepth int) []Block {
+	var blocks []Block
+	var run []*html.Node
+
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		p := Block{Type: BlockParagraph, Text: inlineText(run...)}
+		p.Children = inlineImages(run...)
+		run = run[:0]
+		switch {
+		case p.Text == "" && len(p.Children) > 0:
+			blocks = append(blocks, p.Children...)
+		case p.Text != "":
+			blocks = append(blocks, p)
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && skipTags[c.DataAtom] {
+			continue
+		}
+		if c.Type != html.ElementNode || !isBlockElement(c) {
+			if c.Type == html.TextNode || c.Type == html.ElementNode {
+				run = append(run, c)
+			}
+			continue
+		}
+		flush()
+		blocks = append(blocks, parseBlockElement(c, listDepth)...)
+	}
+	flush()
+	return blocks
+}
+
+// parseBlockElement converts a single block-level element into blocks.
+// <p> and <div> elements that contain other blocks are transparent, except
+// that a <div> with an id or epub:type becomes a section to keep them, and
+// image-only paragraphs are replaced by their images (wrapped in a section
+// when the paragraph has an id or epub:type), so an element may produce
+// zero or several blocks.
+func parseBlockElement(n *html.Node, listDepth int) []Block {
+	b := Block{
+		ID:       navGetAttr(n, "id"),
+		EpubType: strings.Join(strings.Fields(navGetAttr(n, "epub:type")), " "),
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		b.Type = BlockHeading
+		b.Level = headingLevel(n.DataAtom)
+		b.Text = inlineText(n)
+
+	case atom.Ul, atom.Ol, atom.Dl:
+		b.Type = BlockList
+		b.Level = listDepth + 1
+		b.Ordered = n.DataAtom == atom.Ol
+		b.Children = parseListItems(n, b.Level, b.Ordered)
+
+	case atom.Li, atom.Dt, atom.Dd:
+		// Only reached for list items outside a list.
+		b.Type = BlockListItem
+		b.Level = max(listDepth, 1)
+		fillTextBlock(&b, n, b.Level)
+
+	case atom.Blockquote:
+		b.Type = BlockQuote
+		fillTextBlock(&b, n, listDepth)
+
+	case atom.Pre:
+		b.Type = BlockCode
+		b.Text = strings.TrimPrefix(strings.TrimRight(nodeTextContent(n), " \t\r\n"), "\n")
+
+	case atom.Table:
+		b.Type = BlockTable
+		b.Children = parseTableRows(n, listDepth)
+
+	case atom.Tr:
+		b.Type = BlockTableRow
+		b.Children = parseTableCells(n, listDepth)
+
+	case atom.Td, atom.Th:
+		b.Type = BlockTableCell
+		b.Header = n.DataAtom == atom.Th
+		fillTextBlock(&b, n, listDepth)
+
+	case atom.Figure:
+		b.Type = BlockFigure
+		b.Children = parseBlockChildren(n, listDepth)
+
+	case atom.Figcaption, atom.Caption:
+		b.Type = BlockCaption
+		fillTextBlock(&b, n, listDepth)
+
+	case atom.Section, atom.Article, atom.Aside, atom.Nav, atom.Header, atom.Footer, atom.Main:
+		b.Type = BlockSection
+		b.Children = parseBlockChildren(n, listDepth)
+
+	case atom.Hr:
+		b.Type = BlockRule
+
+	default: // <p>, <address>, <div>
+		if containsBlockElement(n) {
+			if n.DataAtom == atom.Div && (b.ID != "" || b.EpubType != "") {
+				b.Type = BlockSection
+				b.Children = parseBlockChildren(n, listDepth)
+				return []Block{b}
+			}
+			return parseBlockChildren(n, listDepth)
+		}
+		b.Type = BlockParagraph
+		fillTextBlock(&b, n, listDepth)
+		if b.Text == "" {
+			// Empty, or an image-only paragraph.
+			if len(b.Children) > 0 && (b.ID != "" || b.EpubType != "") {
+				b.Type = BlockSection
+				return []Block{b}
+			}
+			return b.Children
+		}
+	}
+	return []Block{b}
+}
+
+// fillTextBlock sets Text (and inline images as Children) when n holds only
+// inline content, or Children when it contains nested blocks.
+func fillTextBlock(b *Block, n *html.Node, listDepth int) {
+	if containsBlockElement(n) {
+		b.Children = parseBlockChildren(n, listDepth)
+		return
+	}
+	b.Text = inlineText(n)
+	b.Children = inlineImages(n)
+}
+
+// parseListItems converts the items of a <ul>, <ol>, or <dl> into blocks.
+// Items of an ordered list are numbered from the start attribute (or from
+// the item count when reversed); a value attribute on an item resets the
+// count.
+func parseListItems(list *html.Node, depth int, ordered bool) []Block {
+	var items []*html.Node
+	for c := list.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (c.DataAtom == atom.Li || c.DataAtom == atom.Dt || c.DataAtom == atom.Dd) {
+			items = append(items, c)
+		}
+	}
+
+	reversed := hasAttr(list, "reversed")
+	step, number := 1, 1
+	if reversed {
+		step, number = -1, len(items)
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(navGetAttr(list, "start"))); err == nil {
+		number = v
+	}
+
+	blocks := make([]Block, 0, len(items))
+	for _, li := range items {
+		if v, err := strconv.Atoi(strings.TrimSpace(navGetAttr(li, "value"))); err == nil {
+			number = v
+		}
+		item := Block{
+			Type:     BlockListItem,
+			Level:    depth,
+			ID:       navGetAttr(li, "id"),
+			EpubType: strings.Join(strings.Fields(navGetAttr(li, "epub:type")), " "),
+		}
+		if ordered {
+			item.Number = number
+		}
+		fillTextBlock(&item, li, depth)
+		blocks = append(blocks, item)
+		number += step
+	}
+	return blocks
+}
+
+// parseTableRows returns the caption and rows of a table, looking through
+// <thead>, <tbody>, and <tfoot>.
+func parseTableRows(table *html.Node, listDepth int) []Block {
+	var blocks []Block
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Caption, atom.Tr:
+				blocks = append(blocks, parseBlockElement(c, listDepth)...)
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(c)
+			}
+		}
+	}
+	walk(table)
+	return blocks
+}
+
+// parseTableCells returns the cells of a table row.
+func parseTableCells(tr *html.Node, listDepth int) []Block {
+	var cells []Block
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+			cells = append(cells, parseBlockElement(c, listDepth)...)
+		}
+	}
+	return cells
+}
+
+// blockElements is the set of elements parsed as blocks by Chapter.Blocks.
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Address: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Li: true, atom.Dt: true, atom.Dd: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Hr: true,
+	atom.Table: true, atom.Caption: true, atom.Thead: true, atom.Tbody: true, atom.Tfoot: true,
+	atom.Tr: true, atom.Td: true, atom.Th: true,
+	atom.Figure: true, atom.Figcaption: true,
+	atom.Section: true, atom.Article: true, atom.Aside: true, atom.Nav: true,
+	atom.Header: true, atom.Footer: true, atom.Main: true,
+}
+
+// isBlockElement reports whether n is parsed as a block. Images are inline;
+// image-only content is returned as BlockImage blocks.
+func isBlockElement(n *html.Node) bool {
+	return blockElements[n.DataAtom]
+}
+
+// containsBlockElement reports whether any descendant of n is a block.
+func containsBlockElement(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || skipTags[c.DataAtom] {
+			continue
+		}
+		if isBlockElement(c) || containsBlockElement(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineText returns the text of inline nodes with whitespace collapsed,
+// <br> as a line break, and script, style, and SVG title and description
+// content skipped.
+func inlineText(nodes ...*html.Node) string {
+	var buf strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			buf.WriteString(collapseWhitespace(n.Data))
+		case n.Type != html.ElementNode:
+		case skipTags[n.DataAtom]:
+		case n.Namespace == "svg" && (n.Data == "title" || n.Data == "desc"):
+		case n.DataAtom == atom.Br:
+			buf.WriteByte('\n')
+		default:
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// inlineImages returns an image block for every <img> and SVG <image> among
+// nodes and their descendants.
+func inlineImages(nodes ...*html.Node) []Block {
+	var images []Block
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+		if n.DataAtom == atom.Img || n.DataAtom == atom.Image {
+			src, alt := imageSource(n)
+			images = append(images, Block{
+				Type:     BlockImage,
+				ID:       navGetAttr(n, "id"),
+				EpubType: strings.Join(strings.Fields(navGetAttr(n, "epub:type")), " "),
+				Src:      src,
+				Alt:      alt,
+			})
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return images
+}
+
+// imageSource returns the source and alternative text of an <img> or SVG
+// <image> element. SVG images take their alternative text from the title of
+// the enclosing <svg>, if any.
+func imageSource(n *html.Node) (src, alt string) {
+	if n.DataAtom == atom.Img {
+		return navGetAttr(n, "src"), navGetAttr(n, "alt")
+	}
+	for _, a := range n.Attr {
+		if matchAttr(a, "xlink", "href") || (a.Key == "href" && a.Namespace == "") {
+			src = a.Val
+			break
+		}
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.DataAtom == atom.Svg {
+			for c := p.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode && c.Data == "title" {
+					alt = strings.TrimSpace(collapseWhitespace(nodeTextContent(c)))
+				}
+			}
+			break
+		}
+	}
+	return src, alt
+}
@@ -0,0 +1,139 @@
+package epub
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseBlocks(t *testing.T) {
+	data := []byte(`<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<section id="c1" epub:type="chapter">
+  <h1 id="h">Chapter  One</h1>
+  <p>First <em>line</em><br/> second line.</p>
+  <div><div><p>Nested.</p></div></div>
+  <div id="note" epub:type="footnote"><p>A note.</p></div>
+  Loose <b>text</b>.
+  <ol start="3">
+    <li>Three</li>
+    <li>Four
+      <ul><li id="sub">Sub</li></ul>
+    </li>
+    <li value="10">Ten</li>
+  </ol>
+  <blockquote><p>Quoted.</p></blockquote>
+  <pre>
+x := 1
+  y := 2
+</pre>
+  <table>
+    <caption>Totals</caption>
+    <thead><tr><th>Name</th><th>Qty</th></tr></thead>
+    <tbody><tr><td>Apples</td><td>3</td></tr></tbody>
+  </table>
+  <figure><img src="fig.png" alt="A figure"/><figcaption>Figure 1</figcaption></figure>
+  <p><img src="inline.png" alt=""/></p>
+  <p id="plate" epub:type="z3998:illustration"><img src="plate.png" alt="Plate"/></p>
+  <hr/>
+  <script>var x;</script>
+</section>
+</body></html>`)
+
+	got, err := parseBlocks(data)
+	if err != nil {
+		t.Fatalf("parseBlocks() error = %v", err)
+	}
+	want := []Block{{
+		Type: BlockSection, ID: "c1", EpubType: "chapter",
+		Children: []Block{
+			{Type: BlockHeading, Level: 1, Text: "Chapter One", ID: "h"},
+			{Type: BlockParagraph, Text: "First line\nsecond line."},
+			{Type: BlockParagraph, Text: "Nested."},
+			{Type: BlockSection, ID: "note", EpubType: "footnote", Children: []Block{
+				{Type: BlockParagraph, Text: "A note."},
+			}},
+			{Type: BlockParagraph, Text: "Loose text."},
+			{Type: BlockList, Level: 1, Ordered: true, Children: []Block{
+				{Type: BlockListItem, Level: 1, Number: 3, Text: "Three"},
+				{Type: BlockListItem, Level: 1, Number: 4, Children: []Block{
+					{Type: BlockParagraph, Text: "Four"},
+					{Type: BlockList, Level: 2, Children: []Block{
+						{Type: BlockListItem, Level: 2, ID: "sub", Text: "Sub"},
+					}},
+				}},
+				{Type: BlockListItem, Level: 1, Number: 10, Text: "Ten"},
+			}},
+			{Type: BlockQuote, Children: []Block{{Type: BlockParagraph, Text: "Quoted."}}},
+			{Type: BlockCode, Text: "x := 1\n  y := 2"},
+			{Type: BlockTable, Children: []Block{
+				{Type: BlockCaption, Text: "Totals"},
+				{Type: BlockTableRow, Children: []Block{
+					{Type: BlockTableCell, Header: true, Text: "Name"},
+					{Type: BlockTableCell, Header: true, Text: "Qty"},
+				}},
+				{Type: BlockTableRow, Children: []Block{
+					{Type: BlockTableCell, Text: "Apples"},
+					{Type: BlockTableCell, Text: "3"},
+				}},
+			}},
+			{Type: BlockFigure, Children: []Block{
+				{Type: BlockImage, Src: "fig.png", Alt: "A figure"},
+				{Type: BlockCaption, Text: "Figure 1"},
+			}},
+			{Type: BlockImage, Src: "inline.png"},
+			{Type: BlockSection, ID: "plate", EpubType: "z3998:illustration", Children: []Block{
+				{Type: BlockImage, Src: "plate.png", Alt: "Plate"},
+			}},
+			{Type: BlockRule},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBlocks() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseBlocks_ReversedList(t *testing.T) {
+	got, err := parseBlocks([]byte(`<html><body><ol reversed=""><li>a</li><li>b</li><li>c</li></ol></body></html>`))
+	if err != nil {
+		t.Fatalf("parseBlocks() error = %v", err)
+	}
+	var numbers []int
+	for _, item := range got[0].Children {
+		numbers = append(numbers, item.Number)
+	}
+	if want := []int{3, 2, 1}; !reflect.DeepEqual(numbers, want) {
+		t.Errorf("numbers = %v, want %v", numbers, want)
+	}
+}
+
+func TestChapter_Blocks(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:blocks</dc:identifier>
+    <dc:title>Blocks</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`,
+		"OEBPS/text/ch1.xhtml": `<html xmlns:xlink="http://www.w3.org/1999/xlink"><body>
+<div><svg><title>Cover</title><image xlink:href="../images/cover.jpg"/></svg></div>
+</body></html>`,
+	})
+
+	got, err := book.Chapters()[0].Blocks()
+	if err != nil {
+		t.Fatalf("Blocks() error = %v", err)
+	}
+	want := []Block{{Type: BlockImage, Src: "OEBPS/images/cover.jpg", Alt: "Cover"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Blocks() = %+v, want %+v", got, want)
+	}
+
+	if _, err := (Chapter{}).Blocks(); !errors.Is(err, ErrInvalidChapter) {
+		t.Errorf("zero Chapter Blocks() error = %v, want ErrInvalidChapter", err)
+	}
+}
@@ -0,0 +1,467 @@
+package epub
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// BoilerplateDetector recognises publisher or producer boilerplate in a
+// content document: license pages, colophons, generated title pages,
+// advertisements, and similar text that is not part of the work itself.
+//
+// Detectors are registered on a Book with AddBoilerplateDetector or
+// SetBoilerplateDetectors. Implementations must be safe to call repeatedly
+// and must not retain the document.
+type BoilerplateDetector interface {
+	// Name returns a short machine-readable detector name (e.g., "gutenberg").
+	Name() string
+
+	// Detect inspects a document and reports the boilerplate it contains.
+	// A zero BoilerplateMatch means the document has none.
+	Detect(doc BoilerplateDocument) BoilerplateMatch
+}
+
+// BoilerplateDocument is the view of a content document passed to
+// BoilerplateDetector.Detect.
+type BoilerplateDocument struct {
+	// Href is the ZIP-internal path of the document.
+	Href string
+
+	// SpineIndex is the position of the document in the spine.
+	SpineIndex int
+
+	// Raw is the XHTML content of the document.
+	Raw []byte
+
+	// Text is the plain text of the document, as returned by
+	// Chapter.TextContent.
+	Text string
+
+	// Blocks holds the whitespace-collapsed text of each innermost
+	// block-level element (<p>, <div>, <h1>–<h6>, <li>, ...) in document
+	// order. Blocks without text are omitted. BlockRange indices refer to
+	// this slice.
+	Blocks []string
+}
+
+// BlockRange is a half-open range [Start, End) of BoilerplateDocument.Blocks.
+type BlockRange struct {
+	Start int
+	End   int
+}
+
+// BoilerplateMatch reports the boilerplate a detector found in a document.
+type BoilerplateMatch struct {
+	// Detector is the name of the detector that produced the match. It is
+	// filled in by the library.
+	Detector string
+
+	// Whole is true when the entire document is boilerplate.
+	Whole bool
+
+	// Ranges lists the blocks that are boilerplate when only part of the
+	// document is.
+	Ranges []BlockRange
+}
+
+// Found reports whether the match contains any boilerplate.
+func (m BoilerplateMatch) Found() bool {
+	return m.Whole || len(m.Ranges) > 0
+}
+
+// DefaultBoilerplateDetectors returns the built-in detectors, which are
+// registered on every Book by default:
+//
+//   - "gutenberg": Project Gutenberg license pages, and the header and
+//     footer around the "*** START/END OF THE PROJECT GUTENBERG EBOOK ***"
+//     markers inside a chapter;
+//   - "standard-ebooks": Standard Ebooks imprint, colophon, and
+//     uncopyright pages;
+//   - "feedbooks": Feedbooks title and "Food for the mind" pages;
+//   - "calibre": title pages and book jackets generated by Calibre;
+//   - "retailer-ads": retailer and publisher newsletter sign-up and
+//     "thank you for buying" pages.
+func DefaultBoilerplateDetectors() []BoilerplateDetector {
+	return []BoilerplateDetector{
+		gutenbergDetector{},
+		standardEbooksDetector{},
+		feedbooksDetector{},
+		calibreDetector{},
+		retailerAdDetector{},
+	}
+}
+
+// AddBoilerplateDetector registers an additional detector. It runs after the
+// detectors already registered. Cached boilerplate detection is reset.
+func (b *Book) AddBoilerplateDetector(d BoilerplateDetector) {
+	b.SetBoilerplateDetectors(append(b.boilerplateDetectors(), d)...)
+}
+
+// SetBoilerplateDetectors replaces the registered detectors, including the
+// built-in ones. Call it without arguments to disable boilerplate detection.
+// Cached boilerplate detection is reset.
+func (b *Book) SetBoilerplateDetectors(detectors ...BoilerplateDetector) {
+	b.detectors = append([]BoilerplateDetector{}, detectors...)
+	b.detectorsSet = true
+	b.boilerplateDetected = false
+	for i := range b.chapters {
+		b.chapters[i].IsBoilerplate = false
+	}
+}
+
+// boilerplateDetectors implements the bookReader interface: it returns the
+// registered detectors, or the built-in ones if none were set.
+func (b *Book) boilerplateDetectors() []BoilerplateDetector {
+	if !b.detectorsSet {
+		return DefaultBoilerplateDetectors()
+	}
+	return b.detectors
+}
+
+// detectBoilerplate reads each chapter file and marks chapters that are
+// boilerplate as a whole. It runs at most once per detector configuration.
+func (b *Book) detectBoilerplate() {
+	if b.boilerplateDetected {
+		return
+	}
+	_ = b.Chapters() // ensure chapters are built
+	detectors := b.boilerplateDetectors()
+	for i := range b.chapters {
+		raw, err := b.readFile(b.chapters[i].Href)
+		if err != nil {
+			continue
+		}
+		doc, _, err := newBoilerplateDocument(stripBOM(raw), b.chapters[i].Href, i)
+		if err != nil {
+			continue
+		}
+		for _, m := range runBoilerplateDetectors(detectors, doc) {
+			if m.Whole {
+				b.chapters[i].IsBoilerplate = true
+				break
+			}
+		}
+	}
+	b.boilerplateDetected = true
+}
+
+// DetectBoilerplate runs the book's registered boilerplate detectors on this
+// chapter and returns every match, in detector order.
+func (c Chapter) DetectBoilerplate() ([]BoilerplateMatch, error) {
+	data, err := c.RawContent()
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := newBoilerplateDocument(data, c.Href, c.spineIndex())
+	if err != nil {
+		return nil, err
+	}
+	return runBoilerplateDetectors(c.book.boilerplateDetectors(), doc), nil
+}
+
+// CleanTextContent is like TextContent, but with the boilerplate found by the
+// book's registered detectors removed. It returns "" when the whole chapter
+// is boilerplate.
+func (c Chapter) CleanTextContent() (string, error) {
+	data, err := c.stripBoilerplate()
+	if err != nil || data == nil {
+		return "", err
+	}
+	return extractText(data)
+}
+
+// CleanBodyHTML is like BodyHTML, but with the boilerplate found by the
+// book's registered detectors removed. It returns "" when the whole chapter
+// is boilerplate.
+func (c Chapter) CleanBodyHTML() (string, error) {
+	data, err := c.stripBoilerplate()
+	if err != nil || data == nil {
+		return "", err
+	}
+	data = rewriteImagePaths(data, c.Href)
+	return extractBodyHTML(data)
+}
+
+// spineIndex returns the position of the chapter in the spine, or -1.
+func (c Chapter) spineIndex() int {
+	return c.book.spineIndexOf(c.Href)
+}
+
+// spineIndexOf implements the bookReader interface: it returns the spine
+// position of the document at the ZIP path href, or -1.
+func (b *Book) spineIndexOf(href string) int {
+	if i, ok := b.spineIndexMap()[href]; ok {
+		return i
+	}
+	return -1
+}
+
+// stripBoilerplate returns the chapter's XHTML with every boilerplate block
+// removed, or nil when the whole chapter is boilerplate.
+func (c Chapter) stripBoilerplate() ([]byte, error) {
+	data, err := c.RawContent()
+	if err != nil {
+		return nil, err
+	}
+	doc, nodes, err := newBoilerplateDocument(data, c.Href, c.spineIndex())
+	if err != nil {
+		return nil, err
+	}
+
+	remove := make(map[int]bool)
+	for _, m := range runBoilerplateDetectors(c.book.boilerplateDetectors(), doc) {
+		if m.Whole {
+			return nil, nil
+		}
+		for _, r := range m.Ranges {
+			for i := max(r.Start, 0); i < r.End && i < len(nodes); i++ {
+				remove[i] = true
+			}
+		}
+	}
+	if len(remove) == 0 {
+		return data, nil
+	}
+
+	root := nodes[0]
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	for i := range remove {
+		if n := nodes[i]; n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+	var buf bytes.Buffer
+	if err := html.Render(&buf, root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// runBoilerplateDetectors runs each detector on doc and returns the matches
+// that found boilerplate, with Detector set.
+func runBoilerplateDetectors(detectors []BoilerplateDetector, doc BoilerplateDocument) []BoilerplateMatch {
+	var matches []BoilerplateMatch
+	for _, d := range detectors {
+		if d == nil {
+			continue
+		}
+		m := d.Detect(doc)
+		if !m.Found() {
+			continue
+		}
+		m.Detector = d.Name()
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// newBoilerplateDocument builds the detector view of an XHTML document. It
+// also returns the block elements behind BoilerplateDocument.Blocks.
+func newBoilerplateDocument(data []byte, href string, spineIndex int) (BoilerplateDocument, []*html.Node, error) {
+	doc := BoilerplateDocument{Href: href, SpineIndex: spineIndex, Raw: data}
+	text, err := extractText(data)
+	if err != nil {
+		return doc, nil, err
+	}
+	doc.Text = text
+
+	root, err := html.Parse(bytes.NewReader(normalizeSelfClosingSkipTags(data)))
+	if err != nil {
+		return doc, nil, err
+	}
+	var nodes []*html.Node
+	if body := findElement(root, atom.Body); body != nil {
+		collectTextBlocks(body, &nodes)
+	}
+	doc.Blocks = make([]string, len(nodes))
+	for i, n := range nodes {
+		doc.Blocks[i] = strings.TrimSpace(collapseWhitespace(nodeTextContent(n)))
+	}
+	return doc, nodes, nil
+}
+
+// collectTextBlocks appends the innermost block-level elements with text
+// below n, in document order. Script and style content is ignored.
+func collectTextBlocks(n *html.Node, out *[]*html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || skipTags[c.DataAtom] {
+			continue
+		}
+		if isTextBlock(c) && !containsTextBlock(c) {
+			if strings.TrimSpace(nodeTextContent(c)) != "" {
+				*out = append(*out, c)
+			}
+			continue
+		}
+		collectTextBlocks(c, out)
+	}
+}
+
+// isTextBlock reports whether n is a block-level element that can hold text.
+// <br> and <hr> separate text but never contain it.
+func isTextBlock(n *html.Node) bool {
+	return n.Type == html.ElementNode && blockTags[n.DataAtom] && n.DataAtom != atom.Br && n.DataAtom != atom.Hr
+}
+
+// containsTextBlock reports whether any descendant of n is a text block.
+func containsTextBlock(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if isTextBlock(c) || containsTextBlock(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// --- built-in detectors ---
+
+// gutenbergStartPattern and gutenbergEndPattern match the markers around the
+// text of a Project Gutenberg ebook, in both the current and older forms.
+var (
+	gutenbergStartPattern = regexp.MustCompile(`(?i)\*{3}\s*start of (?:the|this) project gutenberg e-?book`)
+	gutenbergEndPattern   = regexp.MustCompile(`(?i)(?:\*{3}\s*end of (?:the|this) project gutenberg e-?book|^end of (?:the )?project gutenberg(?:'s| e-?book)\b)`)
+)
+
+// gutenbergDetector detects Project Gutenberg license pages and the header
+// and footer around the start and end markers.
+type gutenbergDetector struct{}
+
+func (gutenbergDetector) Name() string { return "gutenberg" }
+
+func (gutenbergDetector) Detect(doc BoilerplateDocument) BoilerplateMatch {
+	var m BoilerplateMatch
+	start, end := -1, -1
+	for i, block := range doc.Blocks {
+		if start < 0 && end < 0 && gutenbergStartPattern.MatchString(block) {
+			start = i
+		}
+		if end < 0 && gutenbergEndPattern.MatchString(block) {
+			end = i
+		}
+	}
+	if start >= 0 {
+		m.Ranges = append(m.Ranges, BlockRange{Start: 0, End: start + 1})
+	}
+	if end >= 0 {
+		m.Ranges = append(m.Ranges, BlockRange{Start: end, End: len(doc.Blocks)})
+	}
+
+	if start < 0 && end < 0 {
+		m.Whole = isGutenbergLicenseText(strings.ToLower(doc.Text))
+	} else {
+		// Nothing left between the header and the footer.
+		first, last := start+1, len(doc.Blocks)
+		if end >= 0 {
+			last = end
+		}
+		m.Whole = first >= last
+	}
+	if m.Whole {
+		m.Ranges = nil
+	}
+	return m
+}
+
+// standardEbooksDetector detects the imprint, colophon, and uncopyright pages
+// that Standard Ebooks adds to every book.
+type standardEbooksDetector struct{}
+
+func (standardEbooksDetector) Name() string { return "standard-ebooks" }
+
+func (standardEbooksDetector) Detect(doc BoilerplateDocument) BoilerplateMatch {
+	lower := strings.ToLower(doc.Text)
+	if !strings.Contains(lower, "standard ebooks") && !strings.Contains(lower, "standardebooks.org") {
+		return BoilerplateMatch{}
+	}
+	for _, w := range fileNameWords(doc.Href) {
+		if w == "imprint" || w == "colophon" || w == "uncopyright" {
+			return BoilerplateMatch{Whole: true}
+		}
+	}
+	for _, m := range contentSemantics(doc.Raw) {
+		for _, typ := range strings.Fields(m.types) {
+			if typ == "imprint" || typ == "colophon" || typ == "copyright-page" {
+				return BoilerplateMatch{Whole: true}
+			}
+		}
+	}
+	return BoilerplateMatch{Whole: strings.Contains(lower, "uncopyright")}
+}
+
+// feedbooksDetector detects the title and closing "Food for the mind" pages
+// of Feedbooks editions.
+type feedbooksDetector struct{}
+
+func (feedbooksDetector) Name() string { return "feedbooks" }
+
+func (feedbooksDetector) Detect(doc BoilerplateDocument) BoilerplateMatch {
+	if len(doc.Text) >= roleTextLimit {
+		return BoilerplateMatch{}
+	}
+	lower := strings.ToLower(doc.Text)
+	if !strings.Contains(lower, "feedbooks") {
+		return BoilerplateMatch{}
+	}
+	whole := strings.Contains(lower, "food for the mind") ||
+		strings.Contains(lower, "www.feedbooks.com") ||
+		strings.Contains(lower, "published by feedbooks")
+	return BoilerplateMatch{Whole: whole}
+}
+
+// calibreDetector detects the cover title page and the book jacket that
+// Calibre generates during conversion.
+type calibreDetector struct{}
+
+func (calibreDetector) Name() string { return "calibre" }
+
+func (calibreDetector) Detect(doc BoilerplateDocument) BoilerplateMatch {
+	raw := bytes.ToLower(doc.Raw)
+	switch {
+	case bytes.Contains(raw, []byte(`name="calibre:cover"`)):
+		return BoilerplateMatch{Whole: true}
+	case bytes.Contains(raw, []byte(`name="calibre-content" content="jacket"`)),
+		bytes.Contains(raw, []byte(`class="cbj_banner"`)):
+		return BoilerplateMatch{Whole: true}
+	}
+	words := fileNameWords(doc.Href)
+	if len(words) == 1 && words[0] == "titlepage" && strings.TrimSpace(doc.Text) == "" &&
+		(bytes.Contains(raw, []byte("<svg")) || bytes.Contains(raw, []byte("<img"))) &&
+		bytes.Contains(raw, []byte("calibre")) {
+		return BoilerplateMatch{Whole: true}
+	}
+	return BoilerplateMatch{}
+}
+
+// retailerAdPatterns match the text of retailer and publisher advertisement
+// pages such as newsletter sign-ups.
+var retailerAdPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)thank you for (?:buying|downloading|purchasing) this\b.*\be-?book`),
+	regexp.MustCompile(`(?i)sign up for (?:our|the) (?:e-?mail )?newsletters?`),
+	regexp.MustCompile(`(?i)join our mailing list`),
+	regexp.MustCompile(`(?i)get the latest news on\b`),
+	regexp.MustCompile(`(?i)discover great authors, exclusive offers, and more`),
+}
+
+// retailerAdDetector detects short advertisement pages inserted by retailers
+// and publishers.
+type retailerAdDetector struct{}
+
+func (retailerAdDetector) Name() string { return "retailer-ads" }
+
+func (retailerAdDetector) Detect(doc BoilerplateDocument) BoilerplateMatch {
+	if len(doc.Text) >= roleTextLimit {
+		return BoilerplateMatch{}
+	}
+	for _, re := range retailerAdPatterns {
+		if re.MatchString(doc.Text) {
+			return BoilerplateMatch{Whole: true}
+		}
+	}
+	return BoilerplateMatch{}
+}
@@ -0,0 +1,276 @@
+package epub
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const boilerplateTestOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:boilerplate</dc:identifier>
+    <dc:title>Boilerplate</dc:title>
+  </metadata>
+  <manifest>
+    <item id="title" href="titlepage.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ads" href="ads.xhtml" media-type="application/xhtml+xml"/>
+    <item id="colophon" href="colophon.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="title"/>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+    <itemref idref="ads"/>
+    <itemref idref="colophon"/>
+  </spine>
+</package>`
+
+func openBoilerplateTestBook(t *testing.T) *Book {
+	t.Helper()
+	return openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": boilerplateTestOPF,
+		"OEBPS/titlepage.xhtml": `<html><head><meta name="calibre:cover" content="true"/></head>
+<body><div><svg><image xlink:href="cover.jpeg"/></svg></div></body></html>`,
+		"OEBPS/ch1.xhtml": `<html><body>
+<p>The Project Gutenberg eBook of Boilerplate</p>
+<p>This ebook is for the use of anyone anywhere.</p>
+<p>*** START OF THE PROJECT GUTENBERG EBOOK BOILERPLATE ***</p>
+<h1>Chapter I</h1>
+<p>It was a dark night.</p>
+</body></html>`,
+		"OEBPS/ch2.xhtml": `<html><body>
+<h1>Chapter II</h1>
+<p>The end.</p>
+<p>*** END OF THE PROJECT GUTENBERG EBOOK BOILERPLATE ***</p>
+<p>Updated editions will replace the previous one.</p>
+</body></html>`,
+		"OEBPS/ads.xhtml": `<html><body><p>Thank you for buying this ebook.</p>
+<p>Sign up for our newsletter to receive special offers.</p></body></html>`,
+		"OEBPS/colophon.xhtml": `<html><body><h2>Colophon</h2>
+<p>This ebook was produced for Standard Ebooks by volunteers.</p></body></html>`,
+	})
+}
+
+func TestBook_ContentChapters_Boilerplate(t *testing.T) {
+	book := openBoilerplateTestBook(t)
+
+	var got []string
+	for _, ch := range book.ContentChapters() {
+		got = append(got, ch.Href)
+	}
+	want := []string{"OEBPS/ch1.xhtml", "OEBPS/ch2.xhtml"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ContentChapters() = %v, want %v", got, want)
+	}
+
+	var flagged []bool
+	for _, ch := range book.Chapters() {
+		flagged = append(flagged, ch.IsBoilerplate)
+	}
+	if want := []bool{true, false, false, true, true}; !reflect.DeepEqual(flagged, want) {
+		t.Errorf("IsBoilerplate = %v, want %v", flagged, want)
+	}
+}
+
+func TestChapter_DetectBoilerplate(t *testing.T) {
+	book := openBoilerplateTestBook(t)
+	chapters := book.Chapters()
+
+	tests := []struct {
+		index int
+		want  []BoilerplateMatch
+	}{
+		{0, []BoilerplateMatch{{Detector: "calibre", Whole: true}}},
+		{1, []BoilerplateMatch{{Detector: "gutenberg", Ranges: []BlockRange{{0, 3}}}}},
+		{2, []BoilerplateMatch{{Detector: "gutenberg", Ranges: []BlockRange{{2, 4}}}}},
+		{3, []BoilerplateMatch{{Detector: "retailer-ads", Whole: true}}},
+		{4, []BoilerplateMatch{{Detector: "standard-ebooks", Whole: true}}},
+	}
+	for _, tt := range tests {
+		got, err := chapters[tt.index].DetectBoilerplate()
+		if err != nil {
+			t.Fatalf("chapters[%d].DetectBoilerplate() error = %v", tt.index, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("chapters[%d].DetectBoilerplate() = %+v, want %+v", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestChapter_CleanContent(t *testing.T) {
+	book := openBoilerplateTestBook(t)
+	chapters := book.Chapters()
+
+	text, err := chapters[1].CleanTextContent()
+	if err != nil {
+		t.Fatalf("CleanTextContent() error = %v", err)
+	}
+	if want := "Chapter I\nIt was a dark night."; strings.TrimSpace(text) != want {
+		t.Errorf("CleanTextContent() = %q, want %q", text, want)
+	}
+
+	body, err := chapters[2].CleanBodyHTML()
+	if err != nil {
+		t.Fatalf("CleanBodyHTML() error = %v", err)
+	}
+	if strings.Contains(body, "GUTENBERG") || strings.Contains(body, "Updated editions") {
+		t.Errorf("CleanBodyHTML() = %q, want footer removed", body)
+	}
+	if !strings.Contains(body, "<p>The end.</p>") {
+		t.Errorf("CleanBodyHTML() = %q, want chapter text kept", body)
+	}
+
+	text, err = chapters[3].CleanTextContent()
+	if err != nil {
+		t.Fatalf("CleanTextContent() error = %v", err)
+	}
+	if text != "" {
+		t.Errorf("CleanTextContent() of ad page = %q, want empty", text)
+	}
+}
+
+type keywordDetector string
+
+func (d keywordDetector) Name() string { return "keyword" }
+
+func (d keywordDetector) Detect(doc BoilerplateDocument) BoilerplateMatch {
+	var m BoilerplateMatch
+	for i, block := range doc.Blocks {
+		if strings.Contains(block, string(d)) {
+			m.Ranges = append(m.Ranges, BlockRange{Start: i, End: i + 1})
+		}
+	}
+	return m
+}
+
+func TestBook_SetBoilerplateDetectors(t *testing.T) {
+	book := openBoilerplateTestBook(t)
+	if n := len(book.ContentChapters()); n != 2 {
+		t.Fatalf("len(ContentChapters()) = %d, want 2", n)
+	}
+
+	book.SetBoilerplateDetectors()
+	if n := len(book.ContentChapters()); n != 5 {
+		t.Errorf("len(ContentChapters()) without detectors = %d, want 5", n)
+	}
+
+	book.AddBoilerplateDetector(keywordDetector("dark"))
+	text, err := book.Chapters()[1].CleanTextContent()
+	if err != nil {
+		t.Fatalf("CleanTextContent() error = %v", err)
+	}
+	if strings.Contains(text, "dark") || !strings.Contains(text, "START OF THE PROJECT GUTENBERG") {
+		t.Errorf("CleanTextContent() with custom detector = %q", text)
+	}
+}
+
+func TestGutenbergDetector(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks []string
+		text   string
+		want   BoilerplateMatch
+	}{
+		{
+			name:   "no markers",
+			blocks: []string{"Chapter 1", "Text."},
+			text:   "Chapter 1\nText.",
+			want:   BoilerplateMatch{},
+		},
+		{
+			name:   "header only",
+			blocks: []string{"Title", "*** START OF THE PROJECT GUTENBERG EBOOK X ***"},
+			text:   "Title\n*** START OF THE PROJECT GUTENBERG EBOOK X ***",
+			want:   BoilerplateMatch{Whole: true},
+		},
+		{
+			name:   "old end line",
+			blocks: []string{"Text.", "End of Project Gutenberg's X, by Y"},
+			text:   "Text.\nEnd of Project Gutenberg's X, by Y",
+			want:   BoilerplateMatch{Ranges: []BlockRange{{1, 2}}},
+		},
+		{
+			name:   "license page",
+			blocks: []string{"*** START OF THE PROJECT GUTENBERG LICENSE ***"},
+			text:   "*** START OF THE PROJECT GUTENBERG LICENSE ***",
+			want:   BoilerplateMatch{Whole: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gutenbergDetector{}.Detect(BoilerplateDocument{Blocks: tt.blocks, Text: tt.text})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBook_ContentChapters_GutenbergHeaderAndFooter(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:gutenberg</dc:identifier>
+    <dc:title>Gutenberg</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="license" href="license.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="ch1"/><itemref idref="ch2"/><itemref idref="license"/></spine>
+</package>`,
+		"OEBPS/ch1.xhtml": `<html><body>
+<p>The Project Gutenberg EBook of Gutenberg, by A. Writer</p>
+<p>This eBook is for the use of anyone anywhere at no cost and with almost no
+restrictions whatsoever. You may copy it, give it away or re-use it under the
+terms of the Project Gutenberg License included with this eBook or online at
+www.gutenberg.org</p>
+<p>*** START OF THIS PROJECT GUTENBERG EBOOK GUTENBERG ***</p>
+<h1>Chapter I</h1>
+<p>It was a dark night.</p>
+</body></html>`,
+		"OEBPS/ch2.xhtml": `<html><body>
+<h1>Chapter II</h1>
+<p>The end.</p>
+<p>End of the Project Gutenberg EBook of Gutenberg, by A. Writer</p>
+<p>*** END OF THIS PROJECT GUTENBERG EBOOK GUTENBERG ***</p>
+<p>To protect the Project Gutenberg-tm mission of promoting the free
+distribution of electronic works, by using or distributing this work you
+agree to comply with all the terms of the Full Project Gutenberg-tm License
+available with this file or online at www.gutenberg.org/license.</p>
+</body></html>`,
+		"OEBPS/license.xhtml": `<html><body>
+<h2>THE FULL PROJECT GUTENBERG LICENSE</h2>
+<p>PLEASE READ THIS BEFORE YOU DISTRIBUTE OR USE THIS WORK</p>
+</body></html>`,
+	})
+
+	var got []string
+	for _, ch := range book.ContentChapters() {
+		got = append(got, ch.Href)
+	}
+	if want := []string{"OEBPS/ch1.xhtml", "OEBPS/ch2.xhtml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ContentChapters() = %v, want %v", got, want)
+	}
+
+	chapters := book.Chapters()
+	text, err := chapters[0].CleanTextContent()
+	if err != nil {
+		t.Fatalf("CleanTextContent() error = %v", err)
+	}
+	if text != "Chapter I\nIt was a dark night." {
+		t.Errorf("CleanTextContent() = %q", text)
+	}
+	text, err = chapters[1].CleanTextContent()
+	if err != nil {
+		t.Fatalf("CleanTextContent() error = %v", err)
+	}
+	if text != "Chapter II\nThe end." {
+		t.Errorf("CleanTextContent() = %q", text)
+	}
+}
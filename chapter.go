@@ -1,7 +1,6 @@
 package epub
 
 import (
-	"bytes"
 	"strings"
 )
 
@@ -23,18 +22,9 @@ var gutenbergComboPatterns = [][2]string{
 	{"full license", "gutenberg"},
 }
 
-// isGutenbergLicense checks whether data (raw XHTML) contains patterns
+// isGutenbergLicenseText checks whether lower-case text contains patterns
 // indicating a Project Gutenberg license page.
-func isGutenbergLicense(data []byte) bool {
-	// Extract text to avoid matching inside tags/attributes.
-	text, err := extractText(data)
-	if err != nil {
-		// Fallback: search raw bytes lowercased.
-		text = string(bytes.ToLower(data))
-	} else {
-		text = strings.ToLower(text)
-	}
-
+func isGutenbergLicenseText(text string) bool {
 	for _, pat := range gutenbergPatterns {
 		if strings.Contains(text, pat) {
 			return true
@@ -48,8 +38,11 @@ func isGutenbergLicense(data []byte) bool {
 	return false
 }
 
-// RawContent reads the raw XHTML bytes of this chapter from the ePub archive.
-// Leading UTF-8 BOM is stripped if present.
+// RawContent reads the XHTML of this chapter from the ePub archive as
+// UTF-8: content in another encoding is transcoded (see Warnings), a leading
+// BOM is stripped, and headings targeted by a synthesized TOC (see
+// SynthesizeTOC) get the ids the TOC links to. Use Book.ReadFile with
+// c.Href for the untouched bytes.
 func (c Chapter) RawContent() ([]byte, error) {
 	if c.book == nil {
 		return nil, ErrInvalidChapter
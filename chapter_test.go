@@ -446,7 +446,7 @@ const regularChapterXHTML = `<?xml version="1.0" encoding="UTF-8"?>
 </body>
 </html>`
 
-func TestIsGutenbergLicense(t *testing.T) {
+func TestGutenbergDetector_LicensePages(t *testing.T) {
 	tests := []struct {
 		name string
 		data string
@@ -463,9 +463,12 @@ func TestIsGutenbergLicense(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isGutenbergLicense([]byte(tt.data))
-			if got != tt.want {
-				t.Errorf("isGutenbergLicense() = %v, want %v", got, tt.want)
+			doc, _, err := newBoilerplateDocument([]byte(tt.data), "OEBPS/page.xhtml", 0)
+			if err != nil {
+				t.Fatalf("newBoilerplateDocument() error = %v", err)
+			}
+			if got := (gutenbergDetector{}).Detect(doc).Whole; got != tt.want {
+				t.Errorf("Detect().Whole = %v, want %v", got, tt.want)
 			}
 		})
 	}
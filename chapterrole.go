@@ -0,0 +1,315 @@
+package epub
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// semanticRoles maps EPUB Structural Semantics terms (and their guide
+// equivalents after guideSemanticType) to chapter roles.
+var semanticRoles = map[string]ChapterRole{
+	"cover":           ChapterRoleCover,
+	"titlepage":       ChapterRoleTitlePage,
+	"halftitlepage":   ChapterRoleTitlePage,
+	"seriespage":      ChapterRoleTitlePage,
+	"copyright-page":  ChapterRoleCopyright,
+	"imprint":         ChapterRoleCopyright,
+	"dedication":      ChapterRoleDedication,
+	"epigraph":        ChapterRoleDedication,
+	"toc":             ChapterRoleTOC,
+	"foreword":        ChapterRoleForeword,
+	"preface":         ChapterRoleForeword,
+	"bodymatter":      ChapterRoleBody,
+	"chapter":         ChapterRoleBody,
+	"part":            ChapterRoleBody,
+	"division":        ChapterRoleBody,
+	"appendix":        ChapterRoleAppendix,
+	"endnotes":        ChapterRoleNotes,
+	"footnotes":       ChapterRoleNotes,
+	"rearnotes":       ChapterRoleNotes,
+	"notes":           ChapterRoleNotes,
+	"index":           ChapterRoleIndex,
+	"colophon":        ChapterRoleColophon,
+	"other-credits":   ChapterRoleColophon,
+	"acknowledgments": ChapterRoleColophon,
+}
+
+// roleKeywords maps lower-case words found in file names and in the first
+// line of a document to chapter roles.
+var roleKeywords = map[string]ChapterRole{
+	"cover":         ChapterRoleCover,
+	"title":         ChapterRoleTitlePage,
+	"titlepage":     ChapterRoleTitlePage,
+	"halftitle":     ChapterRoleTitlePage,
+	"copyright":     ChapterRoleCopyright,
+	"imprint":       ChapterRoleCopyright,
+	"dedication":    ChapterRoleDedication,
+	"toc":           ChapterRoleTOC,
+	"contents":      ChapterRoleTOC,
+	"foreword":      ChapterRoleForeword,
+	"preface":       ChapterRoleForeword,
+	"appendix":      ChapterRoleAppendix,
+	"notes":         ChapterRoleNotes,
+	"endnotes":      ChapterRoleNotes,
+	"footnotes":     ChapterRoleNotes,
+	"index":         ChapterRoleIndex,
+	"colophon":      ChapterRoleColophon,
+	"ads":           ChapterRoleAdvertisement,
+	"advert":        ChapterRoleAdvertisement,
+	"advertisement": ChapterRoleAdvertisement,
+	"alsoby":        ChapterRoleAdvertisement,
+}
+
+// isbnLinePattern matches an ISBN line such as "ISBN 978-0-00-000000-0".
+var isbnLinePattern = regexp.MustCompile(`(?im)^\s*(?:e-?)?isbn(?:-1[03])?\b`)
+
+// alsoByPattern matches the heading of an "Also by ..." advertisement page.
+var alsoByPattern = regexp.MustCompile(`(?i)^\s*(?:also by|other (?:books|titles) by|more from)\b`)
+
+// roleTextLimit is the text length below which a document is considered
+// short enough for copyright and cover heuristics.
+const roleTextLimit = 2000
+
+// BodyChapters returns the chapters in spine order whose Role is
+// ChapterRoleBody, i.e. the main text without front and back matter and
+// without Project Gutenberg license pages. On the first call every chapter
+// is classified (see ClassifyChapters); subsequent calls use the cached
+// result.
+func (b *Book) BodyChapters() []Chapter {
+	b.ClassifyChapters()
+	b.detectLicenses()
+	out := make([]Chapter, 0, len(b.chapters))
+	for _, ch := range b.chapters {
+		if ch.Role == ChapterRoleBody && !ch.IsLicense {
+			out = append(out, ch)
+		}
+	}
+	return copyChapters(out)
+}
+
+// ClassifyChapters sets Chapter.Role on every chapter. The signals are, in
+// order of precedence:
+//
+//  1. epub:type on the document's <body> or top-level <section> elements,
+//     preferring body matter types such as "chapter" when present;
+//  2. ePub 3 landmarks and ePub 2 guide references to the document;
+//  3. words in the file name (e.g., "copyright.xhtml", "toc.html");
+//  4. the document text: a first line such as "Contents" or "Appendix",
+//     "Also by ..." pages, short pages with a copyright symbol or an ISBN
+//     line, and image-only pages at the start of the spine (cover).
+//
+// The file name, first-line keyword, and copyright signals apply only to
+// front and back matter, so that "index.xhtml", a chapter titled "Notes
+// from Underground", or an epigraph quoting a copyright line stays in the
+// body: when a landmark, guide reference, or epub:type marks the start of
+// the body matter, they apply to the documents before it; otherwise they
+// apply to the leading and trailing runs of the spine that they classify
+// as front or back matter, up to the first and from the last body chapter.
+//
+// Documents without any signal are body chapters, except those before the
+// start of the body matter (when a landmark, guide reference, or epub:type
+// marks it), which stay ChapterRoleUnknown. It runs at most once per Book;
+// afterwards Chapters also returns the roles.
+func (b *Book) ClassifyChapters() {
+	if b.rolesClassified {
+		return
+	}
+	_ = b.Chapters() // ensure chapters are built
+
+	navRoles := make(map[int]ChapterRole)
+	bodyStart := -1
+	for _, s := range b.StructuralSemantics() {
+		if s.SpineIndex < 0 {
+			continue
+		}
+		if s.Type == "bodymatter" && (bodyStart < 0 || s.SpineIndex < bodyStart) {
+			bodyStart = s.SpineIndex
+		}
+		if s.Source == SemanticSourceContent {
+			continue
+		}
+		if role, ok := semanticRoles[s.Type]; ok {
+			if _, exists := navRoles[s.SpineIndex]; !exists {
+				navRoles[s.SpineIndex] = role
+			}
+		}
+	}
+
+	classify := func(i int, keywords bool) ChapterRole {
+		ch := b.chapters[i]
+		var data []byte
+		if raw, err := b.readFile(ch.Href); err == nil {
+			data = stripBOM(raw)
+		}
+		role, explicit := classifyChapter(data, ch.Href, i, navRoles[i], keywords)
+		if role == ChapterRoleUnknown && !explicit && (bodyStart < 0 || i >= bodyStart) {
+			role = ChapterRoleBody
+		}
+		return role
+	}
+
+	for i := range b.chapters {
+		b.chapters[i].Role = classify(i, bodyStart < 0 || i < bodyStart)
+	}
+	if bodyStart < 0 {
+		// Without a body matter mark, keyword signals count only in the
+		// leading and trailing runs of front and back matter.
+		first, last := -1, -1
+		for i, ch := range b.chapters {
+			if ch.Role == ChapterRoleBody {
+				if first < 0 {
+					first = i
+				}
+				last = i
+			}
+		}
+		for i := first + 1; first >= 0 && i < last; i++ {
+			if b.chapters[i].Role != ChapterRoleBody {
+				b.chapters[i].Role = classify(i, false)
+			}
+		}
+	}
+	b.rolesClassified = true
+}
+
+// classifyChapter determines the role of a single spine document from its
+// content data, ZIP path href, spine index, and the role derived from
+// landmarks and guide references (navRole). The file name, first-line
+// keyword, and copyright heuristics apply only when keywords is true. It reports explicit =
+// true when the document is marked as generic front or back matter, which
+// prevents it from defaulting to the body.
+func classifyChapter(data []byte, href string, index int, navRole ChapterRole, keywords bool) (role ChapterRole, explicit bool) {
+	role = ChapterRoleUnknown
+	for _, typ := range documentTypes(data) {
+		r, ok := semanticRoles[typ]
+		switch {
+		case ok && r == ChapterRoleBody:
+			return r, true
+		case ok && role == ChapterRoleUnknown:
+			role = r
+		case typ == "frontmatter" || typ == "backmatter":
+			explicit = true
+		}
+	}
+	if role != ChapterRoleUnknown {
+		return role, true
+	}
+
+	if navRole != ChapterRoleUnknown {
+		return navRole, true
+	}
+
+	if keywords {
+		if r := roleFromWords(fileNameWords(href)); r != ChapterRoleUnknown {
+			return r, true
+		}
+	}
+
+	text, err := extractText(data)
+	if err != nil {
+		return ChapterRoleUnknown, explicit
+	}
+	return roleFromText(text, data, index, keywords), explicit
+}
+
+// documentTypes returns the epub:type terms of the <body> element and of the
+// top-level <section> elements (those not nested in another section) of an
+// XHTML document, in document order. Sections count only when the body has
+// no text outside them: an epigraph or footnotes section inside a chapter
+// does not describe the whole document.
+func documentTypes(data []byte) []string {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	var bodyTypes, sectionTypes []string
+	outsideText := false
+	var walk func(*html.Node, bool)
+	walk = func(n *html.Node, inBody bool) {
+		switch {
+		case n.Type == html.TextNode && inBody && strings.TrimSpace(n.Data) != "":
+			outsideText = true
+		case n.Type == html.ElementNode && n.DataAtom == atom.Body:
+			bodyTypes = append(bodyTypes, strings.Fields(navGetAttr(n, "epub:type"))...)
+			inBody = true
+		case n.Type == html.ElementNode && n.DataAtom == atom.Section:
+			sectionTypes = append(sectionTypes, strings.Fields(navGetAttr(n, "epub:type"))...)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inBody)
+		}
+	}
+	walk(doc, false)
+	if outsideText {
+		return bodyTypes
+	}
+	return append(bodyTypes, sectionTypes...)
+}
+
+// roleFromText applies text heuristics to a document's extracted text. The
+// first-line keyword and copyright heuristics apply only when keywords is
+// true.
+func roleFromText(text string, data []byte, index int, keywords bool) ChapterRole {
+	firstLine, _, _ := strings.Cut(text, "\n")
+	firstLine = strings.TrimSpace(firstLine)
+
+	if alsoByPattern.MatchString(firstLine) {
+		return ChapterRoleAdvertisement
+	}
+	if words := strings.Fields(firstLine); keywords && len(words) > 0 && len(words) <= 3 {
+		if r := roleFromWords(lowerWords(firstLine)); r != ChapterRoleUnknown && r != ChapterRoleTitlePage && r != ChapterRoleCover {
+			return r
+		}
+	}
+
+	if keywords && len(text) < roleTextLimit {
+		lower := strings.ToLower(text)
+		if strings.Contains(text, "©") || strings.Contains(lower, "all rights reserved") ||
+			isbnLinePattern.MatchString(text) {
+			return ChapterRoleCopyright
+		}
+	}
+
+	if index <= 1 && strings.TrimSpace(text) == "" {
+		if lower := strings.ToLower(string(data)); strings.Contains(lower, "<img") || strings.Contains(lower, "<svg") {
+			return ChapterRoleCover
+		}
+	}
+	return ChapterRoleUnknown
+}
+
+// roleFromWords returns the role of the first word with a keyword match.
+// Calibre split files ("index_split_001.html") are not index pages.
+func roleFromWords(words []string) ChapterRole {
+	for i, w := range words {
+		if w == "index" && i+1 < len(words) && words[i+1] == "split" {
+			return ChapterRoleUnknown
+		}
+		if r, ok := roleKeywords[w]; ok {
+			return r
+		}
+	}
+	return ChapterRoleUnknown
+}
+
+// fileNameWords splits the base name of href (without extension) into
+// lower-case words at non-letter characters.
+func fileNameWords(href string) []string {
+	base := path.Base(href)
+	base = strings.TrimSuffix(base, path.Ext(base))
+	return lowerWords(base)
+}
+
+// lowerWords splits s into lower-case words at non-letter characters.
+func lowerWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
@@ -0,0 +1,244 @@
+package epub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBook_BodyChapters(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:roles</dc:identifier>
+    <dc:title>Roles</dc:title>
+  </metadata>
+  <manifest>
+    <item id="p0" href="p0.xhtml" media-type="application/xhtml+xml"/>
+    <item id="p1" href="p1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="p2" href="p2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ded" href="dedication.xhtml" media-type="application/xhtml+xml"/>
+    <item id="p4" href="p4.xhtml" media-type="application/xhtml+xml"/>
+    <item id="p5" href="p5.xhtml" media-type="application/xhtml+xml"/>
+    <item id="split1" href="index_split_001.html" media-type="application/xhtml+xml"/>
+    <item id="split2" href="index_split_002.html" media-type="application/xhtml+xml"/>
+    <item id="p8" href="p8.xhtml" media-type="application/xhtml+xml"/>
+    <item id="p9" href="p9.xhtml" media-type="application/xhtml+xml"/>
+    <item id="p10" href="p10.xhtml" media-type="application/xhtml+xml"/>
+    <item id="p11" href="p11.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="p0"/>
+    <itemref idref="p1"/>
+    <itemref idref="p2"/>
+    <itemref idref="ded"/>
+    <itemref idref="p4"/>
+    <itemref idref="p5"/>
+    <itemref idref="split1"/>
+    <itemref idref="split2"/>
+    <itemref idref="p8"/>
+    <itemref idref="p9"/>
+    <itemref idref="p10"/>
+    <itemref idref="p11"/>
+  </spine>
+  <guide>
+    <reference type="title-page" title="Title" href="p1.xhtml"/>
+    <reference type="text" title="Start" href="index_split_001.html"/>
+    <reference type="index" title="Index" href="p11.xhtml"/>
+  </guide>
+</package>`,
+		"OEBPS/p0.xhtml":             `<html><body><div><img src="cover.jpg" alt=""/></div></body></html>`,
+		"OEBPS/p1.xhtml":             `<html><body><h1>Roles</h1><p>A Novel</p></body></html>`,
+		"OEBPS/p2.xhtml":             `<html><body><p>Copyright © 2024 Someone.</p><p>ISBN 978-0-00-000000-0</p></body></html>`,
+		"OEBPS/dedication.xhtml":     `<html><body><p>For my parents.</p></body></html>`,
+		"OEBPS/p4.xhtml":             `<html><body><h1>Contents</h1><p><a href="index_split_001.html">One</a></p></body></html>`,
+		"OEBPS/p5.xhtml":             `<html xmlns:epub="http://www.idpf.org/2007/ops"><body epub:type="frontmatter"><h1>A Note</h1></body></html>`,
+		"OEBPS/index_split_001.html": `<html><body><h1>Chapter One</h1><p>Text.</p></body></html>`,
+		"OEBPS/index_split_002.html": `<html><body><h1>Chapter Two</h1><p>More text.</p></body></html>`,
+		"OEBPS/p8.xhtml":             `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><section epub:type="appendix"><h1>Appendix A</h1></section></body></html>`,
+		"OEBPS/p9.xhtml":             `<html xmlns:epub="http://www.idpf.org/2007/ops"><body epub:type="endnotes"><h2>Notes</h2><p>1. A note.</p></body></html>`,
+		"OEBPS/p10.xhtml":            `<html><body><h2>Also by the Author</h2><p>Another Book</p></body></html>`,
+		"OEBPS/p11.xhtml":            `<html><body><h2>Index</h2><p>apple, 3</p></body></html>`,
+	})
+
+	want := []ChapterRole{
+		ChapterRoleCover,
+		ChapterRoleTitlePage,
+		ChapterRoleCopyright,
+		ChapterRoleDedication,
+		ChapterRoleTOC,
+		ChapterRoleUnknown, // generic front matter
+		ChapterRoleBody,
+		ChapterRoleBody,
+		ChapterRoleAppendix,
+		ChapterRoleNotes,
+		ChapterRoleAdvertisement,
+		ChapterRoleIndex,
+	}
+
+	if roles := chapterRoles(book.Chapters()); !reflect.DeepEqual(roles, make([]ChapterRole, len(want))) {
+		t.Errorf("roles before classification = %v, want all unknown", roles)
+	}
+
+	body := book.BodyChapters()
+	if len(body) != 2 || body[0].Href != "OEBPS/index_split_001.html" || body[1].Href != "OEBPS/index_split_002.html" {
+		t.Errorf("BodyChapters() = %+v, want the two split files", body)
+	}
+	if got := chapterRoles(book.Chapters()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chapters() roles = %v, want %v", got, want)
+	}
+}
+
+func TestBook_ClassifyChapters_DefaultsToBody(t *testing.T) {
+	book, err := Open(buildChapterTestEPub(t))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer book.Close()
+
+	book.ClassifyChapters()
+	for i, ch := range book.Chapters() {
+		if ch.Role != ChapterRoleBody {
+			t.Errorf("Chapters()[%d].Role = %q, want %q", i, ch.Role, ChapterRoleBody)
+		}
+	}
+}
+
+func TestRoleFromWords(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  ChapterRole
+	}{
+		{fileNameWords("OEBPS/Text/copyright-page.xhtml"), ChapterRoleCopyright},
+		{fileNameWords("OEBPS/toc.html"), ChapterRoleTOC},
+		{fileNameWords("OEBPS/index.xhtml"), ChapterRoleIndex},
+		{fileNameWords("OEBPS/index_split_000.html"), ChapterRoleUnknown},
+		{fileNameWords("OEBPS/chapter01.xhtml"), ChapterRoleUnknown},
+		{fileNameWords("OEBPS/broadside.xhtml"), ChapterRoleUnknown},
+	}
+	for _, tt := range tests {
+		if got := roleFromWords(tt.words); got != tt.want {
+			t.Errorf("roleFromWords(%v) = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func chapterRoles(chapters []Chapter) []ChapterRole {
+	roles := make([]ChapterRole, len(chapters))
+	for i, ch := range chapters {
+		roles[i] = ch.Role
+	}
+	return roles
+}
+
+func TestBook_ClassifyChapters_KeywordsAfterBodyStart(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:keywords</dc:identifier>
+    <dc:title>Keywords</dc:title>
+  </metadata>
+  <manifest>
+    <item id="toc" href="toc.xhtml" media-type="application/xhtml+xml"/>
+    <item id="index" href="index.xhtml" media-type="application/xhtml+xml"/>
+    <item id="notes" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="toc"/><itemref idref="index"/><itemref idref="notes"/></spine>
+  <guide><reference type="text" title="Start" href="index.xhtml"/></guide>
+</package>`,
+		"OEBPS/toc.xhtml":   `<html><body><h1>Contents</h1></body></html>`,
+		"OEBPS/index.xhtml": `<html><body><h1>Chapter One</h1><p>Text.</p></body></html>`,
+		"OEBPS/ch2.xhtml":   `<html><body><h1>Notes from Underground</h1><p>I am a sick man.</p><p>© 1864. All rights reserved.</p></body></html>`,
+	})
+
+	book.ClassifyChapters()
+	want := []ChapterRole{ChapterRoleTOC, ChapterRoleBody, ChapterRoleBody}
+	if got := chapterRoles(book.Chapters()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chapters() roles = %v, want %v", got, want)
+	}
+	if n := len(book.BodyChapters()); n != 2 {
+		t.Errorf("len(BodyChapters()) = %d, want 2", n)
+	}
+}
+
+func TestClassifyChapter_NestedSections(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want ChapterRole
+	}{
+		{
+			name: "epigraph inside untyped body",
+			data: `<html><body><h1>One</h1><section epub:type="epigraph"><p>Quote.</p></section><p>Text.</p></body></html>`,
+			want: ChapterRoleUnknown,
+		},
+		{
+			name: "epigraph page",
+			data: `<html><body epub:type="frontmatter"><section epub:type="epigraph"><p>Quote.</p></section></body></html>`,
+			want: ChapterRoleDedication,
+		},
+		{
+			name: "footnotes nested in chapter",
+			data: `<html><body><section epub:type="chapter"><h1>One</h1><section epub:type="footnotes"><p>1. Note.</p></section></section></body></html>`,
+			want: ChapterRoleBody,
+		},
+		{
+			name: "chapter preferred over epigraph",
+			data: `<html><body><section epub:type="epigraph"><p>Quote.</p></section><section epub:type="chapter"><h1>One</h1></section></body></html>`,
+			want: ChapterRoleBody,
+		},
+		{
+			name: "body type",
+			data: `<html><body epub:type="backmatter index"><section epub:type="epigraph"><p>Quote.</p></section></body></html>`,
+			want: ChapterRoleIndex,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := classifyChapter([]byte(tt.data), "OEBPS/ch.xhtml", 3, ChapterRoleUnknown, false); got != tt.want {
+				t.Errorf("classifyChapter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBook_ClassifyChapters_KeywordsInOuterRuns(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:outer-runs</dc:identifier>
+    <dc:title>Outer Runs</dc:title>
+  </metadata>
+  <manifest>
+    <item id="copyright" href="copyright.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="notes" href="notes.xhtml" media-type="application/xhtml+xml"/>
+    <item id="epigraph" href="ch3.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch4" href="ch4.xhtml" media-type="application/xhtml+xml"/>
+    <item id="index" href="index.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="copyright"/>
+    <itemref idref="ch1"/>
+    <itemref idref="notes"/>
+    <itemref idref="epigraph"/>
+    <itemref idref="ch4"/>
+    <itemref idref="index"/>
+  </spine>
+</package>`,
+		"OEBPS/copyright.xhtml": `<html><body><p>Text.</p></body></html>`,
+		"OEBPS/ch1.xhtml":       `<html><body><h1>One</h1><p>Text.</p></body></html>`,
+		"OEBPS/notes.xhtml":     `<html><body><h1>Two</h1><p>Text.</p></body></html>`,
+		"OEBPS/ch3.xhtml":       `<html><body><p>A quoted poem.</p><p>© 1923 A. Poet. All rights reserved.</p></body></html>`,
+		"OEBPS/ch4.xhtml":       `<html><body><h1>Four</h1><p>Text.</p></body></html>`,
+		"OEBPS/index.xhtml":     `<html><body><h1>Index</h1><p>apple, 3</p></body></html>`,
+	})
+
+	book.ClassifyChapters()
+	want := []ChapterRole{ChapterRoleCopyright, ChapterRoleBody, ChapterRoleBody, ChapterRoleBody, ChapterRoleBody, ChapterRoleIndex}
+	if got := chapterRoles(book.Chapters()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chapters() roles = %v, want %v", got, want)
+	}
+}
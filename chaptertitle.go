@@ -0,0 +1,99 @@
+package epub
+
+import "strings"
+
+// ResolveChapterTitles fills in the Title of chapters that the TOC does not
+// name. It is opt-in: Chapters only uses TOC entries pointing to the chapter
+// file. For each chapter without a title the following sources are tried in
+// order, and Chapter.TitleSource records the one used:
+//
+//  1. the deepest TOC entry whose SpineIndex/SpineEndIndex range covers the
+//     chapter (TitleSourceTOCRange);
+//  2. the XHTML <title>, unless it merely repeats the book title
+//     (TitleSourceDocument);
+//  3. the first <h1>–<h6> heading (TitleSourceHeading);
+//  4. an ePub 3 landmarks label (TitleSourceLandmark);
+//  5. an ePub 2 guide reference title (TitleSourceGuide).
+//
+// The resolved titles are kept in the cached chapters, so later calls to
+// Chapters and ContentChapters return them. ResolveChapterTitles returns
+// the number of titles it filled in.
+func (b *Book) ResolveChapterTitles() int {
+	_ = b.Chapters() // ensure chapters are built
+
+	resolved := 0
+	for i := range b.chapters {
+		ch := &b.chapters[i]
+		if ch.Title != "" {
+			continue
+		}
+		if title, src := b.resolveChapterTitle(i, ch.Href); title != "" {
+			ch.Title, ch.TitleSource = title, src
+			resolved++
+		}
+	}
+	return resolved
+}
+
+// resolveChapterTitle returns the fallback title for the spine item at index
+// i with ZIP path href, and its source.
+func (b *Book) resolveChapterTitle(i int, href string) (string, TitleSource) {
+	if title := b.coveringTOCTitle(i); title != "" {
+		return title, TitleSourceTOCRange
+	}
+
+	if data, err := b.readFile(href); err == nil {
+		data = stripBOM(data)
+		if title := documentTitleElement(data); title != "" && !b.isBookTitle(title) {
+			return title, TitleSourceDocument
+		}
+		if headings, _ := scanHeadings(data); len(headings) > 0 {
+			return headings[0].text, TitleSourceHeading
+		}
+	}
+
+	for _, lm := range b.landmarks {
+		if hrefWithoutFragment(lm.Href) == href && lm.Title != "" {
+			return lm.Title, TitleSourceLandmark
+		}
+	}
+	for _, ref := range b.guide {
+		if hrefWithoutFragment(b.resolveOPFPath(ref.Href)) == href {
+			if title := strings.TrimSpace(ref.Title); title != "" {
+				return title, TitleSourceGuide
+			}
+		}
+	}
+	return "", TitleSourceNone
+}
+
+// coveringTOCTitle returns the title of the deepest TOC entry whose spine
+// range [SpineIndex, SpineEndIndex) contains spine index i. Among entries
+// of equal depth the last one in reading order wins.
+func (b *Book) coveringTOCTitle(i int) string {
+	best := -1
+	entries := b.TOCIndex().entries
+	for _, e := range entries {
+		if e.Title == "" || e.SpineIndex < 0 || i < e.SpineIndex || i >= e.SpineEndIndex {
+			continue
+		}
+		if best < 0 || e.Depth >= entries[best].Depth {
+			best = e.Index
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return entries[best].Title
+}
+
+// isBookTitle reports whether title equals one of the book's titles,
+// ignoring case and whitespace.
+func (b *Book) isBookTitle(title string) bool {
+	for _, t := range b.metadata.Titles {
+		if sameTOCTitle(t, title) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package epub
+
+import "testing"
+
+func TestBook_ResolveChapterTitles(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:titles</dc:identifier>
+    <dc:title>The Book</dc:title>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="intro" href="intro.xhtml" media-type="application/xhtml+xml"/>
+    <item id="preface" href="preface.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ded" href="ded.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1b" href="ch1b.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="cover"/>
+    <itemref idref="intro"/>
+    <itemref idref="preface"/>
+    <itemref idref="ded"/>
+    <itemref idref="ch1"/>
+    <itemref idref="ch1b"/>
+    <itemref idref="ch2"/>
+  </spine>
+  <guide>
+    <reference type="cover" title="Cover Page" href="cover.xhtml"/>
+  </guide>
+</package>`,
+		"OEBPS/nav.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<nav epub:type="toc"><ol>
+  <li><a href="ch1.xhtml">Chapter 1</a></li>
+  <li><a href="ch2.xhtml">Chapter 2</a></li>
+</ol></nav>
+<nav epub:type="landmarks"><ol>
+  <li><a epub:type="dedication" href="ded.xhtml">Dedication</a></li>
+</ol></nav>
+</body></html>`,
+		"OEBPS/cover.xhtml":   `<html><body><img src="c.jpg" alt=""/></body></html>`,
+		"OEBPS/intro.xhtml":   `<html><head><title>Introduction</title></head><body><h1>Intro heading</h1></body></html>`,
+		"OEBPS/preface.xhtml": `<html><head><title>the  book</title></head><body><h2>Preface</h2></body></html>`,
+		"OEBPS/ded.xhtml":     `<html><body><p>For my parents.</p></body></html>`,
+		"OEBPS/ch1.xhtml":     `<html><body><h1>One</h1></body></html>`,
+		"OEBPS/ch1b.xhtml":    `<html><head><title>Part two of one</title></head><body><p>More.</p></body></html>`,
+		"OEBPS/ch2.xhtml":     `<html><body><h1>Two</h1></body></html>`,
+	})
+
+	before := book.Chapters()
+	if before[4].TitleSource != TitleSourceTOC || before[0].TitleSource != TitleSourceNone {
+		t.Errorf("TitleSource before resolving = %q, %q, want %q, %q",
+			before[4].TitleSource, before[0].TitleSource, TitleSourceTOC, TitleSourceNone)
+	}
+
+	if n := book.ResolveChapterTitles(); n != 5 {
+		t.Errorf("ResolveChapterTitles() = %d, want 5", n)
+	}
+
+	want := []struct {
+		title  string
+		source TitleSource
+	}{
+		{"Cover Page", TitleSourceGuide},
+		{"Introduction", TitleSourceDocument},
+		{"Preface", TitleSourceHeading},
+		{"Dedication", TitleSourceLandmark},
+		{"Chapter 1", TitleSourceTOC},
+		{"Chapter 1", TitleSourceTOCRange},
+		{"Chapter 2", TitleSourceTOC},
+	}
+	chapters := book.Chapters()
+	for i, w := range want {
+		if chapters[i].Title != w.title || chapters[i].TitleSource != w.source {
+			t.Errorf("Chapters()[%d] = %q (%q), want %q (%q)",
+				i, chapters[i].Title, chapters[i].TitleSource, w.title, w.source)
+		}
+	}
+
+	if n := book.ResolveChapterTitles(); n != 0 {
+		t.Errorf("second ResolveChapterTitles() = %d, want 0", n)
+	}
+}
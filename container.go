@@ -22,33 +22,33 @@ type rootFile struct {
 // containerPath is the well-known location of container.xml in an ePub archive.
 const containerPath = "META-INF/container.xml"
 
-// parseContainer locates and parses the OPF path from the ePub ZIP archive.
+// parseContainer locates and parses the OPF path from the book's ZIP archive.
 //
 // It first tries META-INF/container.xml (case-insensitive lookup). If the file
 // is missing, it falls back to scanning all ZIP entries for a ".opf" file.
 // Returns a wrapped ErrInvalidEPub if no OPF path can be determined.
-func parseContainer(zr *zip.Reader) (string, error) {
+func (b *Book) parseContainer() (string, error) {
 	// Try container.xml first.
-	if f := findFileInsensitive(zr, containerPath); f != nil {
-		return parseContainerXML(f)
+	if f := findFileInsensitive(b.zip, containerPath); f != nil {
+		return b.parseContainerXML(f)
 	}
 
 	// Fallback: scan for .opf files.
-	return fallbackFindOPF(zr)
+	return fallbackFindOPF(b.zip)
 }
 
 // parseContainerXML reads and decodes a container.xml ZIP entry, returning
-// the full-path of the first rootfile.
-func parseContainerXML(f *zip.File) (string, error) {
+// the full-path of the first rootfile. A warning is recorded when the file
+// is not UTF-8 (see transcode).
+func (b *Book) parseContainerXML(f *zip.File) (string, error) {
 	data, err := readZipFile(f)
 	if err != nil {
 		return "", fmt.Errorf("epub: read container.xml: %w", err)
 	}
-
-	data = stripBOM(data)
+	data = b.transcode(f.Name, data)
 
 	var c containerXML
-	if err := xml.Unmarshal(data, &c); err != nil {
+	if err := decodeXML(data, &c); err != nil {
 		return "", fmt.Errorf("epub: parse container.xml: %w", err)
 	}
 
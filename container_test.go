@@ -2,7 +2,10 @@ package epub
 
 import (
 	"errors"
+	"strings"
 	"testing"
+
+	"golang.org/x/text/encoding/unicode"
 )
 
 // validContainerXML is a well-formed META-INF/container.xml pointing to an OPF.
@@ -19,7 +22,7 @@ func TestParseContainer_Normal(t *testing.T) {
 		"OEBPS/content.opf":      `<package/>`,
 	})
 
-	opfPath, err := parseContainer(zr)
+	opfPath, err := (&Book{zip: zr}).parseContainer()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -34,7 +37,7 @@ func TestParseContainer_CaseInsensitive(t *testing.T) {
 		"OEBPS/content.opf":      `<package/>`,
 	})
 
-	opfPath, err := parseContainer(zr)
+	opfPath, err := (&Book{zip: zr}).parseContainer()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -50,7 +53,7 @@ func TestParseContainer_WithBOM(t *testing.T) {
 		"OEBPS/content.opf":      `<package/>`,
 	})
 
-	opfPath, err := parseContainer(zr)
+	opfPath, err := (&Book{zip: zr}).parseContainer()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -65,7 +68,7 @@ func TestParseContainer_FallbackOPF(t *testing.T) {
 		"content.opf": `<package/>`,
 	})
 
-	opfPath, err := parseContainer(zr)
+	opfPath, err := (&Book{zip: zr}).parseContainer()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -79,7 +82,7 @@ func TestParseContainer_FallbackOPF_CaseInsensitive(t *testing.T) {
 		"OEBPS/Book.OPF": `<package/>`,
 	})
 
-	opfPath, err := parseContainer(zr)
+	opfPath, err := (&Book{zip: zr}).parseContainer()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -94,7 +97,7 @@ func TestParseContainer_NoOPF(t *testing.T) {
 		"readme.txt": "hello",
 	})
 
-	_, err := parseContainer(zr)
+	_, err := (&Book{zip: zr}).parseContainer()
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -113,7 +116,7 @@ func TestParseContainer_EmptyRootfiles(t *testing.T) {
 		"META-INF/container.xml": emptyContainer,
 	})
 
-	_, err := parseContainer(zr)
+	_, err := (&Book{zip: zr}).parseContainer()
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -134,7 +137,7 @@ func TestParseContainer_EmptyFullPath(t *testing.T) {
 		"META-INF/container.xml": badContainer,
 	})
 
-	_, err := parseContainer(zr)
+	_, err := (&Book{zip: zr}).parseContainer()
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -157,7 +160,7 @@ func TestParseContainer_PrefersRootfileByMediaType(t *testing.T) {
 		"META-INF/container.xml": multiRootContainer,
 	})
 
-	opfPath, err := parseContainer(zr)
+	opfPath, err := (&Book{zip: zr}).parseContainer()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -180,7 +183,7 @@ func TestParseContainer_FallbackToFirstNonEmptyRootfile(t *testing.T) {
 		"META-INF/container.xml": multiRootContainer,
 	})
 
-	opfPath, err := parseContainer(zr)
+	opfPath, err := (&Book{zip: zr}).parseContainer()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -188,3 +191,23 @@ func TestParseContainer_FallbackToFirstNonEmptyRootfile(t *testing.T) {
 		t.Errorf("opfPath = %q, want %q", opfPath, "OPS/first-non-empty.opf")
 	}
 }
+
+func TestParseContainer_TranscodeWarning(t *testing.T) {
+	utf16 := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	zr := buildTestZip(t, map[string]string{
+		"META-INF/container.xml": mustEncode(t, utf16, strings.Replace(validContainerXML, "UTF-8", "UTF-16", 1)),
+		"OEBPS/content.opf":      `<package/>`,
+	})
+
+	b := &Book{zip: zr}
+	opfPath, err := b.parseContainer()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opfPath != "OEBPS/content.opf" {
+		t.Errorf("got %q, want %q", opfPath, "OEBPS/content.opf")
+	}
+	if want := "META-INF/container.xml: transcoded from utf-16le to UTF-8"; len(b.warnings) != 1 || b.warnings[0] != want {
+		t.Errorf("warnings = %v, want [%q]", b.warnings, want)
+	}
+}
@@ -0,0 +1,181 @@
+package epub
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// w3cdtfPattern matches W3CDTF dates (a profile of ISO 8601) with optional
+// trailing components: YYYY, YYYY-MM, YYYY-MM-DD, and timestamps with
+// minutes, optional (fractional) seconds, and an optional zone designator.
+// A space is accepted in place of "T" since it is common in the wild.
+var w3cdtfPattern = regexp.MustCompile(
+	`^(\d{4})(?:-(\d{2})(?:-(\d{2})(?:[T ](\d{2}):(\d{2})(?::(\d{2})(\.\d+)?)?\s*(Z|z|[+-]\d{2}:?\d{2})?)?)?)?$`)
+
+// isoBasicDatePattern matches ISO 8601 basic-format calendar dates (YYYYMMDD).
+var isoBasicDatePattern = regexp.MustCompile(`^(\d{4})(\d{2})(\d{2})$`)
+
+// calibreUndefinedDate is the sentinel Calibre writes for an unknown date.
+const calibreUndefinedDate = "0101-01-01"
+
+// ParsePartialDate parses a W3CDTF / ISO 8601 date such as "2024",
+// "2024-06", "2024-06-01", or "2024-06-01T12:30:00Z". Values that cannot be
+// parsed are returned with Precision set to DatePrecisionNone and Raw set to
+// the trimmed input, so callers can still display them.
+func ParsePartialDate(s string) PartialDate {
+	raw := strings.TrimSpace(s)
+	pd := PartialDate{Raw: raw}
+	if raw == "" || strings.HasPrefix(raw, calibreUndefinedDate) {
+		return pd
+	}
+
+	var m []string
+	if bm := isoBasicDatePattern.FindStringSubmatch(raw); bm != nil {
+		m = []string{bm[0], bm[1], bm[2], bm[3], "", "", "", "", ""}
+	} else if m = w3cdtfPattern.FindStringSubmatch(raw); m == nil {
+		return pd
+	}
+
+	year, _ := strconv.Atoi(m[1])
+	month, day := 1, 1
+	var hour, minute, sec, nsec int
+	precision := DatePrecisionYear
+
+	if m[2] != "" {
+		month, _ = strconv.Atoi(m[2])
+		precision = DatePrecisionMonth
+	}
+	if m[3] != "" {
+		day, _ = strconv.Atoi(m[3])
+		precision = DatePrecisionDay
+	}
+	if m[4] != "" {
+		hour, _ = strconv.Atoi(m[4])
+		minute, _ = strconv.Atoi(m[5])
+		precision = DatePrecisionMinute
+	}
+	if m[6] != "" {
+		sec, _ = strconv.Atoi(m[6])
+		precision = DatePrecisionSecond
+	}
+	if m[7] != "" {
+		// Fraction includes the leading "."; scale to nanoseconds.
+		frac := m[7][1:]
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		nsec, _ = strconv.Atoi(frac + strings.Repeat("0", 9-len(frac)))
+	}
+
+	loc := time.UTC
+	if zone := m[8]; zone != "" && zone != "Z" && zone != "z" {
+		digits := strings.ReplaceAll(zone[1:], ":", "")
+		zh, _ := strconv.Atoi(digits[:2])
+		zm, _ := strconv.Atoi(digits[2:])
+		if zh > 23 || zm > 59 {
+			return pd
+		}
+		offset := zh*3600 + zm*60
+		if zone[0] == '-' {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+
+	if month < 1 || month > 12 || day < 1 || hour > 23 || minute > 59 || sec > 60 {
+		return pd
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, sec, nsec, loc)
+	// Reject dates that time.Date normalised (e.g., February 30).
+	if t.Day() != day || int(t.Month()) != month {
+		return pd
+	}
+
+	pd.Time = t
+	pd.Precision = precision
+	return pd
+}
+
+// IsZero reports whether d holds no parsed date.
+func (d PartialDate) IsZero() bool {
+	return d.Precision == DatePrecisionNone
+}
+
+// Year returns the year of d, or 0 if d could not be parsed.
+func (d PartialDate) Year() int {
+	if d.IsZero() {
+		return 0
+	}
+	return d.Time.Year()
+}
+
+// String formats d in W3CDTF at its recorded precision. Unparsed values
+// return Raw unchanged.
+func (d PartialDate) String() string {
+	switch d.Precision {
+	case DatePrecisionYear:
+		return d.Time.Format("2006")
+	case DatePrecisionMonth:
+		return d.Time.Format("2006-01")
+	case DatePrecisionDay:
+		return d.Time.Format("2006-01-02")
+	case DatePrecisionMinute:
+		return d.Time.Format("2006-01-02T15:04Z07:00")
+	case DatePrecisionSecond:
+		return d.Time.Format(time.RFC3339Nano)
+	default:
+		return d.Raw
+	}
+}
+
+// extractDates parses every dc:date element and the dcterms:modified meta,
+// filling md.Dates, md.Published, and md.Modified.
+func extractDates(md *Metadata, om *opfMetadata) {
+	var fallbackPublished PartialDate
+	for _, d := range om.Dates {
+		if strings.TrimSpace(d.Value) == "" {
+			continue
+		}
+		event := strings.ToLower(strings.TrimSpace(d.Event))
+		pd := ParsePartialDate(d.Value)
+		md.Dates = append(md.Dates, EventDate{Event: event, Date: pd})
+
+		switch event {
+		case "publication":
+			if md.Published.Raw == "" {
+				md.Published = pd
+			}
+		case "modification":
+			if md.Modified.Raw == "" {
+				md.Modified = pd
+			}
+		case "":
+			if fallbackPublished.Raw == "" {
+				fallbackPublished = pd
+			}
+		}
+	}
+	if md.Published.Raw == "" {
+		md.Published = fallbackPublished
+	}
+
+	// ePub 3 dcterms:modified takes precedence over an ePub 2 modification event.
+	for _, m := range om.Metas {
+		if m.Refines != "" {
+			continue
+		}
+		v := m.Value
+		if m.Property != "dcterms:modified" {
+			if m.Name != "dcterms:modified" {
+				continue
+			}
+			v = m.Content
+		}
+		if strings.TrimSpace(v) != "" {
+			md.Modified = ParsePartialDate(v)
+			break
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package epub
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePartialDate(t *testing.T) {
+	tests := []struct {
+		in        string
+		precision DatePrecision
+		want      time.Time
+		str       string
+	}{
+		{"2024", DatePrecisionYear, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "2024"},
+		{" 2024-06 ", DatePrecisionMonth, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "2024-06"},
+		{"2024-06-15", DatePrecisionDay, time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), "2024-06-15"},
+		{"20240615", DatePrecisionDay, time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), "2024-06-15"},
+		{"2024-06-15T10:30Z", DatePrecisionMinute, time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC), "2024-06-15T10:30Z"},
+		{"2024-06-15T10:30:45Z", DatePrecisionSecond, time.Date(2024, 6, 15, 10, 30, 45, 0, time.UTC), "2024-06-15T10:30:45Z"},
+		{"2024-06-15 10:30:45.25", DatePrecisionSecond, time.Date(2024, 6, 15, 10, 30, 45, 250000000, time.UTC), "2024-06-15T10:30:45.25Z"},
+		{"2024-06-15T10:30:45+02:00", DatePrecisionSecond, time.Date(2024, 6, 15, 8, 30, 45, 0, time.UTC), "2024-06-15T10:30:45+02:00"},
+		{"2024-06-15T10:30:45-0530", DatePrecisionSecond, time.Date(2024, 6, 15, 16, 0, 45, 0, time.UTC), "2024-06-15T10:30:45-05:30"},
+	}
+	for _, tt := range tests {
+		got := ParsePartialDate(tt.in)
+		if got.Precision != tt.precision {
+			t.Errorf("ParsePartialDate(%q).Precision = %v, want %v", tt.in, got.Precision, tt.precision)
+			continue
+		}
+		if !got.Time.Equal(tt.want) {
+			t.Errorf("ParsePartialDate(%q).Time = %v, want %v", tt.in, got.Time, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("ParsePartialDate(%q).String() = %q, want %q", tt.in, got.String(), tt.str)
+		}
+		if got.Year() != tt.want.Year() {
+			t.Errorf("ParsePartialDate(%q).Year() = %d, want %d", tt.in, got.Year(), tt.want.Year())
+		}
+	}
+}
+
+func TestParsePartialDate_Invalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"June 2024",
+		"2024-13",
+		"2024-02-30",
+		"2024-06-15T25:00Z",
+		"2024-06-15T10:30+24:00",
+		"0101-01-01T00:00:00+00:00", // Calibre's "undefined" sentinel
+	}
+	for _, in := range inputs {
+		got := ParsePartialDate(in)
+		if !got.IsZero() {
+			t.Errorf("ParsePartialDate(%q) = %+v, want unparsed", in, got)
+		}
+		if got.Year() != 0 {
+			t.Errorf("ParsePartialDate(%q).Year() = %d, want 0", in, got.Year())
+		}
+		if got.String() != got.Raw {
+			t.Errorf("ParsePartialDate(%q).String() = %q, want raw %q", in, got.String(), got.Raw)
+		}
+	}
+}
+
+func TestExtractMetadata_DatesV2Events(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+    <dc:date opf:event="creation">1999-05</dc:date>
+    <dc:date opf:event="publication">2001</dc:date>
+    <dc:date opf:event="modification">2010-02-03</dc:date>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+	pkg, err := parseOPF([]byte(opf))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	md := extractMetadata(pkg)
+
+	if md.Date != "1999-05" {
+		t.Errorf("Date = %q, want %q", md.Date, "1999-05")
+	}
+	if md.Published.String() != "2001" || md.Published.Precision != DatePrecisionYear {
+		t.Errorf("Published = %+v, want year 2001", md.Published)
+	}
+	if md.Modified.String() != "2010-02-03" {
+		t.Errorf("Modified = %q, want %q", md.Modified.String(), "2010-02-03")
+	}
+	if len(md.Dates) != 3 {
+		t.Fatalf("Dates count = %d, want 3", len(md.Dates))
+	}
+	wantEvents := []string{"creation", "publication", "modification"}
+	for i, ev := range wantEvents {
+		if md.Dates[i].Event != ev {
+			t.Errorf("Dates[%d].Event = %q, want %q", i, md.Dates[i].Event, ev)
+		}
+	}
+}
+
+func TestExtractMetadata_DatesV3Modified(t *testing.T) {
+	pkg, err := parseOPF([]byte(testMetadataOPFv3))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	md := extractMetadata(pkg)
+
+	if md.Published.String() != "2024-06-01" {
+		t.Errorf("Published = %q, want %q", md.Published.String(), "2024-06-01")
+	}
+	want := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	if !md.Modified.Time.Equal(want) || md.Modified.Precision != DatePrecisionSecond {
+		t.Errorf("Modified = %+v, want %v at second precision", md.Modified, want)
+	}
+	if len(md.Dates) != 1 || md.Dates[0].Event != "" {
+		t.Errorf("Dates = %+v, want one untagged date", md.Dates)
+	}
+}
+
+func TestExtractMetadata_NoDates(t *testing.T) {
+	pkg, err := parseOPF([]byte(testMetadataOPFMinimal))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	md := extractMetadata(pkg)
+
+	if !md.Published.IsZero() || !md.Modified.IsZero() || md.Dates != nil {
+		t.Errorf("expected no dates, got Published=%+v Modified=%+v Dates=%+v", md.Published, md.Modified, md.Dates)
+	}
+}
@@ -0,0 +1,253 @@
+package epub
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Vendor display-options files.
+const (
+	appleDisplayOptionsPath = "META-INF/com.apple.ibooks.display-options.xml"
+	koboDisplayOptionsPath  = "META-INF/com.kobobooks.display-options.xml"
+)
+
+// xmlDisplayOptions models the <display_options> root shared by the Apple
+// and Kobo display-options files.
+type xmlDisplayOptions struct {
+	XMLName   xml.Name             `xml:"display_options"`
+	Platforms []xmlDisplayPlatform `xml:"platform"`
+}
+
+type xmlDisplayPlatform struct {
+	Name    string             `xml:"name,attr"`
+	Options []xmlDisplayOption `xml:"option"`
+}
+
+type xmlDisplayOption struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// parseDisplayOptionsXML decodes a display-options file into a flat
+// option map. Options of the "*" platform take precedence; options of other
+// platforms only fill in names not set for "*", in document order.
+func parseDisplayOptionsXML(data []byte) (map[string]string, error) {
+	var doc xmlDisplayOptions
+	if err := decodeXML(data, &doc); err != nil {
+		return nil, err
+	}
+
+	opts := make(map[string]string)
+	fill := func(p xmlDisplayPlatform) {
+		for _, o := range p.Options {
+			name := strings.ToLower(strings.TrimSpace(o.Name))
+			if _, exists := opts[name]; name != "" && !exists {
+				opts[name] = strings.ToLower(strings.TrimSpace(o.Value))
+			}
+		}
+	}
+	for _, p := range doc.Platforms {
+		if strings.TrimSpace(p.Name) == "*" {
+			fill(p)
+		}
+	}
+	for _, p := range doc.Platforms {
+		if strings.TrimSpace(p.Name) != "*" {
+			fill(p)
+		}
+	}
+	return opts, nil
+}
+
+// displaySource is one source of display options and its flat option map.
+type displaySource struct {
+	name string
+	opts map[string]string
+}
+
+// parseDisplayOptions reads the vendor display-options files and legacy
+// fixed-layout metas, merges them with the OPF rendition properties into
+// b.displayOptions, and records conflicts as warnings.
+func (b *Book) parseDisplayOptions() {
+	var sources []displaySource
+	for _, vf := range []struct{ name, path string }{
+		{"apple", appleDisplayOptionsPath},
+		{"kobo", koboDisplayOptionsPath},
+	} {
+		f := b.findFile(vf.path)
+		if f == nil {
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			b.warnings = append(b.warnings, fmt.Sprintf("failed to read %s display options: %v", vf.name, err))
+			continue
+		}
+		opts, err := parseDisplayOptionsXML(data)
+		if err != nil {
+			b.warnings = append(b.warnings, fmt.Sprintf("failed to parse %s display options: %v", vf.name, err))
+			continue
+		}
+		sources = append(sources, displaySource{vf.name, opts})
+	}
+	if opts := legacyDisplayMetas(b.opf.Metadata.Metas); len(opts) > 0 {
+		sources = append(sources, displaySource{"opf-meta", opts})
+	}
+
+	b.displayOptions = b.mergeDisplayOptions(sources)
+}
+
+// legacyDisplayMetas collects the pre-ePub 3 fixed-layout metas
+// (<meta name="fixed-layout" content="true"/> and friends).
+func legacyDisplayMetas(metas []opfMeta) map[string]string {
+	opts := make(map[string]string)
+	for _, m := range metas {
+		if m.Name == "" {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(m.Name))
+		switch name {
+		case "fixed-layout", "orientation-lock", "original-resolution", "book-type", "open-to-spread":
+			if _, exists := opts[name]; !exists {
+				opts[name] = strings.ToLower(strings.TrimSpace(m.Content))
+			}
+		}
+	}
+	return opts
+}
+
+// mergeDisplayOptions merges the vendor sources (highest precedence first)
+// and reconciles them with the OPF rendition properties, which win on
+// conflict. Disagreements are recorded as warnings.
+func (b *Book) mergeDisplayOptions(sources []displaySource) DisplayOptions {
+	var d DisplayOptions
+	r := b.rendition
+
+	if r.Layout != "" || r.Orientation != "" || r.Spread != "" {
+		d.Sources = append(d.Sources, "opf")
+	}
+	for _, src := range sources {
+		d.Sources = append(d.Sources, src.name)
+	}
+
+	// lookup returns the highest-precedence value of a boolean or string
+	// option, warning when lower-precedence sources disagree.
+	lookup := func(name string) (string, string, bool) {
+		var val, from string
+		found := false
+		for _, src := range sources {
+			v, ok := src.opts[name]
+			if !ok || v == "" {
+				continue
+			}
+			if !found {
+				val, from, found = v, src.name, true
+				continue
+			}
+			if v != val {
+				b.warnings = append(b.warnings, fmt.Sprintf(
+					"display option %q conflicts: %s says %q, %s says %q; using %s",
+					name, from, val, src.name, v, from))
+			}
+		}
+		return val, from, found
+	}
+
+	if v, from, ok := lookup("fixed-layout"); ok {
+		d.FixedLayout = v == "true"
+		if r.Layout != "" && d.FixedLayout != r.IsFixedLayout() {
+			b.warnings = append(b.warnings, fmt.Sprintf(
+				"%s display option fixed-layout=%s conflicts with rendition:layout %q; using rendition:layout",
+				from, v, r.Layout))
+		}
+	}
+	if r.Layout != "" {
+		d.FixedLayout = r.IsFixedLayout()
+	}
+
+	if v, from, ok := lookup("open-to-spread"); ok {
+		d.OpenToSpread = v == "true"
+		if (d.OpenToSpread && r.Spread == "none") || (!d.OpenToSpread && r.Spread == "both") {
+			b.warnings = append(b.warnings, fmt.Sprintf(
+				"%s display option open-to-spread=%s conflicts with rendition:spread %q; using rendition:spread",
+				from, v, r.Spread))
+		}
+	}
+	switch r.Spread {
+	case "none":
+		d.OpenToSpread = false
+	case "both":
+		d.OpenToSpread = true
+	}
+	if v, _, ok := lookup("specified-fonts"); ok {
+		d.SpecifiedFonts = v == "true"
+	}
+	if v, _, ok := lookup("interactive"); ok {
+		d.Interactive = v == "true"
+	}
+	if v, _, ok := lookup("book-type"); ok {
+		d.BookType = v
+	}
+	if v, _, ok := lookup("original-resolution"); ok {
+		d.OriginalResolution = parseResolution(v)
+	}
+
+	if v, from, ok := lookup("orientation-lock"); ok {
+		d.OrientationLock = v
+		if conflictsWithOrientation(v, r.Orientation) {
+			b.warnings = append(b.warnings, fmt.Sprintf(
+				"%s display option orientation-lock=%s conflicts with rendition:orientation %q; using rendition:orientation",
+				from, v, r.Orientation))
+		}
+	}
+	switch r.Orientation {
+	case "landscape":
+		d.OrientationLock = "landscape-only"
+	case "portrait":
+		d.OrientationLock = "portrait-only"
+	case "auto":
+		d.OrientationLock = "none"
+	}
+
+	return d
+}
+
+// conflictsWithOrientation reports whether an orientation-lock value
+// contradicts a declared rendition:orientation.
+func conflictsWithOrientation(lock, orientation string) bool {
+	switch orientation {
+	case "landscape":
+		return lock != "landscape-only"
+	case "portrait":
+		return lock != "portrait-only"
+	case "auto":
+		return lock != "none"
+	}
+	return false
+}
+
+// parseResolution parses an original-resolution value such as "1024x768".
+func parseResolution(s string) Viewport {
+	w, h, ok := strings.Cut(strings.ToLower(s), "x")
+	if !ok {
+		return Viewport{}
+	}
+	width, err1 := strconv.Atoi(strings.TrimSpace(w))
+	height, err2 := strconv.Atoi(strings.TrimSpace(h))
+	if err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+		return Viewport{}
+	}
+	return Viewport{Width: width, Height: height}
+}
+
+// DisplayOptions returns the normalised vendor display options. It merges
+// Apple iBooks and Kobo display-options files and legacy fixed-layout
+// metas with the OPF rendition properties. Conflicts are reported in
+// Warnings.
+func (b *Book) DisplayOptions() DisplayOptions {
+	d := b.displayOptions
+	d.Sources = append([]string(nil), d.Sources...)
+	return d
+}
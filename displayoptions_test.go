@@ -0,0 +1,209 @@
+package epub
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const appleDisplayOptionsXML = `<?xml version="1.0" encoding="UTF-8"?>
+<display_options>
+  <platform name="iphone">
+    <option name="orientation-lock">portrait-only</option>
+    <option name="interactive">true</option>
+  </platform>
+  <platform name="*">
+    <option name="fixed-layout">true</option>
+    <option name="open-to-spread">TRUE</option>
+    <option name="specified-fonts">true</option>
+    <option name="orientation-lock">landscape-only</option>
+  </platform>
+</display_options>`
+
+func displayOptionsTestOPF(meta string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Picture Book</dc:title>
+    ` + meta + `
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+}
+
+func openDisplayOptionsTestBook(t *testing.T, opf string, extra map[string]string) *Book {
+	t.Helper()
+	files := map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": validContainerXML,
+		"OEBPS/content.opf":      opf,
+	}
+	for k, v := range extra {
+		files[k] = v
+	}
+	data := buildTestEPubBytes(t, files)
+	book, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	t.Cleanup(func() { book.Close() })
+	return book
+}
+
+func TestParseDisplayOptionsXML(t *testing.T) {
+	opts, err := parseDisplayOptionsXML([]byte(appleDisplayOptionsXML))
+	if err != nil {
+		t.Fatalf("parseDisplayOptionsXML() error = %v", err)
+	}
+	want := map[string]string{
+		"fixed-layout":     "true",
+		"open-to-spread":   "true",
+		"specified-fonts":  "true",
+		"orientation-lock": "landscape-only", // "*" wins over "iphone"
+		"interactive":      "true",           // only set for "iphone"
+	}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("options = %v, want %v", opts, want)
+	}
+
+	if _, err := parseDisplayOptionsXML([]byte("<display_options><platform>")); err == nil {
+		t.Error("expected error for malformed XML")
+	}
+}
+
+func TestBook_DisplayOptions_Apple(t *testing.T) {
+	book := openDisplayOptionsTestBook(t,
+		displayOptionsTestOPF(`<meta name="original-resolution" content="1024x768"/><meta name="book-type" content="children"/>`),
+		map[string]string{appleDisplayOptionsPath: appleDisplayOptionsXML})
+
+	want := DisplayOptions{
+		FixedLayout:        true,
+		OpenToSpread:       true,
+		SpecifiedFonts:     true,
+		Interactive:        true,
+		OrientationLock:    "landscape-only",
+		OriginalResolution: Viewport{Width: 1024, Height: 768},
+		BookType:           "children",
+		Sources:            []string{"apple", "opf-meta"},
+	}
+	if got := book.DisplayOptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DisplayOptions() = %+v, want %+v", got, want)
+	}
+	if len(book.Warnings()) != 0 {
+		t.Errorf("unexpected warnings: %v", book.Warnings())
+	}
+}
+
+func TestBook_DisplayOptions_VendorConflict(t *testing.T) {
+	kobo := `<display_options><platform name="*"><option name="fixed-layout">false</option></platform></display_options>`
+	book := openDisplayOptionsTestBook(t, displayOptionsTestOPF(""), map[string]string{
+		appleDisplayOptionsPath: appleDisplayOptionsXML,
+		koboDisplayOptionsPath:  kobo,
+	})
+
+	if !book.DisplayOptions().FixedLayout {
+		t.Error("FixedLayout = false, want true (Apple takes precedence over Kobo)")
+	}
+	if !containsWarning(book.Warnings(), `display option "fixed-layout" conflicts`) {
+		t.Errorf("missing vendor conflict warning: %v", book.Warnings())
+	}
+}
+
+func TestBook_DisplayOptions_RenditionConflict(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <meta property="rendition:layout">reflowable</meta>
+    <meta property="rendition:orientation">portrait</meta>
+    <meta property="rendition:spread">none</meta>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+	book := openDisplayOptionsTestBook(t, opf, map[string]string{appleDisplayOptionsPath: appleDisplayOptionsXML})
+
+	d := book.DisplayOptions()
+	if d.FixedLayout || d.OrientationLock != "portrait-only" || d.OpenToSpread {
+		t.Errorf("DisplayOptions() = %+v, want rendition values to win", d)
+	}
+	if !reflect.DeepEqual(d.Sources, []string{"opf", "apple"}) {
+		t.Errorf("Sources = %v, want [opf apple]", d.Sources)
+	}
+	warnings := book.Warnings()
+	if !containsWarning(warnings, "conflicts with rendition:layout") {
+		t.Errorf("missing layout conflict warning: %v", warnings)
+	}
+	if !containsWarning(warnings, "conflicts with rendition:orientation") {
+		t.Errorf("missing orientation conflict warning: %v", warnings)
+	}
+	if !containsWarning(warnings, `open-to-spread=true conflicts with rendition:spread "none"`) {
+		t.Errorf("missing spread conflict warning: %v", warnings)
+	}
+}
+
+func TestBook_DisplayOptions_SpreadBoth(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <meta property="rendition:spread">both</meta>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+	kobo := `<display_options><platform name="*"><option name="open-to-spread">false</option></platform></display_options>`
+	book := openDisplayOptionsTestBook(t, opf, map[string]string{koboDisplayOptionsPath: kobo})
+
+	if !book.DisplayOptions().OpenToSpread {
+		t.Error("OpenToSpread = false, want true from rendition:spread both")
+	}
+	if !containsWarning(book.Warnings(), `open-to-spread=false conflicts with rendition:spread "both"`) {
+		t.Errorf("missing spread conflict warning: %v", book.Warnings())
+	}
+}
+
+func TestBook_DisplayOptions_None(t *testing.T) {
+	book := openDisplayOptionsTestBook(t, displayOptionsTestOPF(""), nil)
+
+	if got := book.DisplayOptions(); !reflect.DeepEqual(got, DisplayOptions{}) {
+		t.Errorf("DisplayOptions() = %+v, want zero value", got)
+	}
+}
+
+func TestBook_DisplayOptions_MalformedFile(t *testing.T) {
+	book := openDisplayOptionsTestBook(t, displayOptionsTestOPF(""), map[string]string{
+		koboDisplayOptionsPath: "<display_options><platform",
+	})
+
+	if !containsWarning(book.Warnings(), "failed to parse kobo display options") {
+		t.Errorf("missing parse warning: %v", book.Warnings())
+	}
+}
+
+func TestParseResolution(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Viewport
+	}{
+		{"1024x768", Viewport{Width: 1024, Height: 768}},
+		{" 600 X 800 ", Viewport{Width: 600, Height: 800}},
+		{"1024", Viewport{}},
+		{"axb", Viewport{}},
+	}
+	for _, tt := range tests {
+		if got := parseResolution(tt.in); got != tt.want {
+			t.Errorf("parseResolution(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// containsWarning reports whether any warning contains substr.
+func containsWarning(warnings []string, substr string) bool {
+	for _, w := range warnings {
+		if strings.Contains(w, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -17,7 +17,8 @@
 // # Metadata
 //
 // The [Book.Metadata] method returns a [Metadata] struct containing titles, authors,
-// language, identifiers (ISBN/UUID), publisher, date, description, subjects, and more:
+// language, identifiers (ISBN/UUID), publisher, date, description, subjects,
+// series/collections, and more:
 //
 //	md := book.Metadata()
 //	fmt.Println(md.Titles[0])
@@ -42,7 +43,43 @@
 //	    fmt.Println(ch.Title, len(text))
 //	}
 //
-// Use [Book.ContentChapters] to exclude Project Gutenberg license pages.
+// [Chapter.Blocks] returns the content as a tree of typed [Block] values
+// (headings, paragraphs, lists, quotes, code, tables, figures, images) for
+// callers that need the document structure rather than flat text.
+//
+// [Chapter.TextContentWith] extracts text as configured by [TextOptions]:
+// preserved <pre> whitespace, image alt text, skipped hidden elements, list
+// markers, table cells, removal of invisible characters, NFC normalisation,
+// and <ruby> handling.
+//
+// [Book.Notes] resolves footnote and endnote references to their note bodies,
+// and [Chapter.TextContentWithNotes] extracts text with notes omitted,
+// inlined, or collected at the end of the chapter.
+//
+// [Chapter.Markdown] converts a chapter to GitHub Flavored Markdown, with
+// notes as footnotes ([^n]) and links to other chapters rewritten to in-page
+// anchors; [Book.WriteMarkdown] writes the content chapters as one document.
+// [Book.WriteSingleHTML] writes the whole book as one self-contained HTML
+// file, with a table of contents, embedded images and media, and
+// stylesheets scoped to the chapters that use them. [Book.WriteText] writes
+// a plain-text book with a title block and TOC-based chapter headings,
+// wrapped as configured by [TextExportOptions] (East Asian wide characters
+// count as two columns).
+//
+// Use [Book.ContentChapters] to exclude Project Gutenberg license pages and
+// other boilerplate pages. Boilerplate is recognised by [BoilerplateDetector]
+// implementations; the built-in ones ([DefaultBoilerplateDetectors]) can be
+// extended with [Book.AddBoilerplateDetector]. [Chapter.CleanTextContent] and
+// [Chapter.CleanBodyHTML] also strip boilerplate inside a chapter, such as the
+// Project Gutenberg header before the "*** START OF ..." marker.
+//
+// Content documents, the OPF, the NCX, and container.xml in a legacy
+// encoding (e.g., windows-1252, Shift_JIS, UTF-16) are detected from the
+// byte order mark, XML declaration, or <meta> charset and transcoded to
+// UTF-8; each transcoded file is reported by [Book.Warnings]. The package's
+// XML files (container.xml, the OPF, the NCX, encryption.xml) are decoded
+// leniently: HTML named entities such as &hellip; or &alpha; and entities
+// declared in a DTD internal subset are accepted.
 //
 // # Cover Image
 //
@@ -62,7 +99,9 @@
 //   - [ErrInvalidChapter] – a Chapter handle is invalid
 //   - [ErrFileNotFound] – a requested file is not in the archive
 //   - [ErrNoCover] – no cover image could be detected
+//   - [ErrNotInSpine] – a TOC entry does not point to a spine document
 //
 // If no table of contents is present, [Book.TOC] returns an empty slice
-// and [Book.HasTOC] returns false.
+// and [Book.HasTOC] returns false. [Book.SynthesizeTOC] can then build one
+// from the headings of the spine documents.
 package epub
@@ -66,10 +66,8 @@ func checkDRM(zr *zip.Reader) (fontObfuscation bool, err error) {
 	if err != nil {
 		return false, err
 	}
-	data = stripBOM(data)
-
 	var enc xmlEncryption
-	if err := xml.Unmarshal(data, &enc); err != nil {
+	if err := decodeXML(data, &enc); err != nil {
 		// If we can't parse it, treat conservatively as potential DRM.
 		return false, ErrDRMProtected
 	}
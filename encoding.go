@@ -0,0 +1,192 @@
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// xmlEncodingPattern matches the encoding pseudo-attribute of an XML
+// declaration. The value is in submatch 1.
+var xmlEncodingPattern = regexp.MustCompile(`^\s*<\?xml[^>]*?\bencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// metaCharsetPattern matches <meta charset="..."> and the charset parameter
+// of <meta http-equiv="Content-Type" content="...">.
+var metaCharsetPattern = regexp.MustCompile(`(?i)<meta\b[^>]*?\bcharset\s*=\s*["']?([A-Za-z0-9._:-]+)`)
+
+// encodingSniffLimit is the number of leading bytes searched for an XML
+// declaration or meta charset.
+const encodingSniffLimit = 4096
+
+// detectEncoding determines the character encoding of an XML or XHTML
+// document from, in order: a byte order mark, the UTF-16 byte pattern of
+// "<?", the XML declaration, a <meta> charset, and finally sniffing (valid
+// UTF-8, else windows-1252). Valid UTF-8 takes precedence over a <meta>
+// charset, which XML parsers ignore and converters often leave stale, and
+// over a single-byte encoding in the XML declaration. Valid UTF-8 with
+// non-ASCII bytes declared as a multibyte encoding such as gb2312 or
+// shift_jis is read as UTF-8 unless it also decodes cleanly in the declared
+// encoding; conflict then holds the declared label, whichever is chosen. It
+// returns the encoding label and the length of the byte order mark.
+func detectEncoding(data []byte) (label string, bomLen int, conflict string) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8", 3, ""
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return "utf-16le", 2, ""
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return "utf-16be", 2, ""
+	case bytes.HasPrefix(data, []byte{'<', 0, '?', 0}):
+		return "utf-16le", 0, ""
+	case bytes.HasPrefix(data, []byte{0, '<', 0, '?'}):
+		return "utf-16be", 0, ""
+	}
+
+	head := data
+	if len(head) > encodingSniffLimit {
+		head = head[:encodingSniffLimit]
+	}
+	validUTF8 := utf8.Valid(data)
+	declared := ""
+	if m := xmlEncodingPattern.FindSubmatch(head); m != nil {
+		declared = strings.ToLower(string(m[1]))
+		switch {
+		case !validUTF8:
+		case isSingleByteEncoding(declared):
+			declared = ""
+		case isMultiByteEncoding(declared) && !isASCII(data):
+			conflict = declared
+			if !decodesCleanly(data, declared) {
+				declared = ""
+			}
+		}
+	} else if m := metaCharsetPattern.FindSubmatch(head); m != nil && !validUTF8 {
+		declared = strings.ToLower(string(m[1]))
+	}
+	// Without a byte order mark or NUL bytes the document cannot really be
+	// UTF-16; such declarations are common mislabels of ASCII-compatible
+	// content.
+	if declared != "" && !strings.HasPrefix(declared, "utf-16") && declared != "utf16" {
+		return declared, 0, conflict
+	}
+
+	if validUTF8 {
+		return "utf-8", 0, conflict
+	}
+	return "windows-1252", 0, ""
+}
+
+// isSingleByteEncoding reports whether label names a single-byte encoding
+// such as windows-1252 or ISO-8859-2.
+func isSingleByteEncoding(label string) bool {
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return false
+	}
+	_, ok := enc.(*charmap.Charmap)
+	return ok
+}
+
+// isMultiByteEncoding reports whether label names a known encoding other
+// than a single-byte encoding or a Unicode encoding form.
+func isMultiByteEncoding(label string) bool {
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return false
+	}
+	name, _ := htmlindex.Name(enc)
+	return !isSingleByteEncoding(label) && name != "utf-8" && !strings.HasPrefix(name, "utf-16")
+}
+
+// isASCII reports whether data holds only ASCII bytes.
+func isASCII(data []byte) bool {
+	for _, c := range data {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// decodesCleanly reports whether data decodes in the encoding label without
+// invalid sequences.
+func decodesCleanly(data []byte, label string) bool {
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return false
+	}
+	out, err := enc.NewDecoder().Bytes(data)
+	return err == nil && !bytes.ContainsRune(out, utf8.RuneError)
+}
+
+// toUTF8 transcodes an XML or XHTML document to UTF-8 (see detectEncoding)
+// and rewrites the encoding of its XML declaration to UTF-8. It returns the
+// canonical name of the source encoding when the data was transcoded, or ""
+// when it was already UTF-8 (or the encoding is unknown), in which case data
+// is returned unchanged.
+func toUTF8(data []byte) ([]byte, string, error) {
+	label, bomLen, _ := detectEncoding(data)
+	return decodeAs(data, label, bomLen)
+}
+
+// decodeAs transcodes data from the encoding label, skipping a byte order
+// mark of bomLen bytes, as toUTF8 does.
+func decodeAs(data []byte, label string, bomLen int) ([]byte, string, error) {
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return data, "", nil
+	}
+	name, _ := htmlindex.Name(enc)
+	if name == "utf-8" {
+		return data, "", nil
+	}
+
+	out, err := enc.NewDecoder().Bytes(data[bomLen:])
+	if err != nil {
+		return data, "", fmt.Errorf("epub: decode %s: %w", name, err)
+	}
+	if m := xmlEncodingPattern.FindSubmatchIndex(out); m != nil {
+		out = append(out[:m[2]:m[2]], append([]byte("UTF-8"), out[m[3]:]...)...)
+	}
+	return out, name, nil
+}
+
+// transcode converts the file name's data to UTF-8 and records a warning
+// the first time a file is transcoded, cannot be decoded, or is valid UTF-8
+// declared as a multibyte encoding.
+func (b *Book) transcode(name string, data []byte) []byte {
+	label, bomLen, conflict := detectEncoding(data)
+	out, enc, err := decodeAs(data, label, bomLen)
+	if b.encodingWarned[name] || (err == nil && enc == "" && conflict == "") {
+		return out
+	}
+	if b.encodingWarned == nil {
+		b.encodingWarned = make(map[string]bool)
+	}
+	b.encodingWarned[name] = true
+	switch {
+	case err != nil:
+		b.warnings = append(b.warnings, fmt.Sprintf("%s: %v", name, err))
+	case enc == "":
+		b.warnings = append(b.warnings, fmt.Sprintf("%s: declared as %s but valid UTF-8; read as UTF-8", name, conflict))
+	case conflict != "":
+		b.warnings = append(b.warnings, fmt.Sprintf("%s: valid UTF-8 but declared as %s; transcoded from %s to UTF-8", name, conflict, enc))
+	default:
+		b.warnings = append(b.warnings, fmt.Sprintf("%s: transcoded from %s to UTF-8", name, enc))
+	}
+	return out
+}
+
+// readContent reads a file from the archive and transcodes it to UTF-8.
+func (b *Book) readContent(name string) ([]byte, error) {
+	data, err := b.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.transcode(name, data), nil
+}
@@ -0,0 +1,204 @@
+package epub
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func mustEncode(t *testing.T, enc encoding.Encoding, s string) string {
+	t.Helper()
+	out, err := enc.NewEncoder().String(s)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return out
+}
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		label  string
+		bomLen int
+	}{
+		{"utf-8 bom", "\xEF\xBB\xBF<html/>", "utf-8", 3},
+		{"utf-16le bom", "\xFF\xFE<\x00", "utf-16le", 2},
+		{"utf-16be bom", "\xFE\xFF\x00<", "utf-16be", 2},
+		{"utf-16le no bom", "<\x00?\x00x\x00", "utf-16le", 0},
+		{"xml declaration", "<?xml version=\"1.0\" encoding=\"Windows-1252\"?><html>\xE9</html>", "windows-1252", 0},
+		{"xml declaration multi-byte", `<?xml version="1.0" encoding="GB2312"?><html/>`, "gb2312", 0},
+		{"meta charset", "<html><head><meta charset=\"shift_jis\"/></head>\x93\xfa</html>", "shift_jis", 0},
+		{"meta http-equiv", "<html><head><meta http-equiv=\"Content-Type\" content=\"text/html; charset=GB2312\"/></head>\xd6\xd0\xce</html>", "gb2312", 0},
+		{"stale meta over utf-8", `<html><head><meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"/></head>café</html>`, "utf-8", 0},
+		{"stale single-byte declaration over utf-8", `<?xml version="1.0" encoding="ISO-8859-1"?><html>café</html>`, "utf-8", 0},
+		{"utf-8 declared gb2312", `<?xml version="1.0" encoding="GB2312"?><p>中文。</p>`, "utf-8", 0},
+		{"utf-8 declared shift_jis", `<?xml version="1.0" encoding="Shift_JIS"?><p>日本語</p>`, "utf-8", 0},
+		{"mislabelled utf-16", `<?xml version="1.0" encoding="UTF-16"?><html>é</html>`, "utf-8", 0},
+		{"sniff utf-8", "<html>é</html>", "utf-8", 0},
+		{"sniff windows-1252", "<html>\xE9</html>", "windows-1252", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, bomLen, _ := detectEncoding([]byte(tt.data))
+			if label != tt.label || bomLen != tt.bomLen {
+				t.Errorf("detectEncoding() = (%q, %d), want (%q, %d)", label, bomLen, tt.label, tt.bomLen)
+			}
+		})
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     string
+		encoding string
+	}{
+		{
+			name: "utf-8 unchanged",
+			data: `<?xml version="1.0" encoding="utf-8"?><p>é</p>`,
+			want: `<?xml version="1.0" encoding="utf-8"?><p>é</p>`,
+		},
+		{
+			name:     "iso-8859-1",
+			data:     "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><p>caf\xE9 \x93q\x94</p>",
+			want:     `<?xml version="1.0" encoding="UTF-8"?><p>café “q”</p>`,
+			encoding: "windows-1252",
+		},
+		{
+			name:     "gb2312",
+			data:     `<?xml version="1.0" encoding="GB2312"?><p>` + mustEncode(t, simplifiedchinese.GBK, "中文") + `</p>`,
+			want:     `<?xml version="1.0" encoding="UTF-8"?><p>中文</p>`,
+			encoding: "gbk",
+		},
+		{
+			name:     "shift_jis meta",
+			data:     `<html><head><meta charset="Shift_JIS"/></head><body>` + mustEncode(t, japanese.ShiftJIS, "日本語") + `</body></html>`,
+			want:     `<html><head><meta charset="Shift_JIS"/></head><body>日本語</body></html>`,
+			encoding: "shift_jis",
+		},
+		{
+			name: "utf-8 declared gb2312",
+			data: `<?xml version="1.0" encoding="gb2312"?><p>中文。</p>`,
+			want: `<?xml version="1.0" encoding="gb2312"?><p>中文。</p>`,
+		},
+		{
+			name: "stale meta charset",
+			data: `<html><head><meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"/></head><body>café</body></html>`,
+			want: `<html><head><meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"/></head><body>café</body></html>`,
+		},
+		{
+			name:     "utf-16le with bom",
+			data:     mustEncode(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), `<?xml version="1.0" encoding="UTF-16"?><p>é</p>`),
+			want:     `<?xml version="1.0" encoding="UTF-8"?><p>é</p>`,
+			encoding: "utf-16le",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, enc, err := toUTF8([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("toUTF8() error = %v", err)
+			}
+			if string(got) != tt.want || enc != tt.encoding {
+				t.Errorf("toUTF8() = (%q, %q), want (%q, %q)", got, enc, tt.want, tt.encoding)
+			}
+		})
+	}
+}
+
+func TestBook_NonUTF8Content(t *testing.T) {
+	utf16 := unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": mustEncode(t, utf16, `<?xml version="1.0" encoding="UTF-16"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:encoding</dc:identifier>
+    <dc:title>Café</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx"><itemref idref="ch1"/></spine>
+</package>`),
+		"OEBPS/toc.ncx": mustEncode(t, charmap.Windows1252, `<?xml version="1.0" encoding="windows-1252"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="n1" playOrder="1"><navLabel><text>Première</text></navLabel><content src="ch1.xhtml"/></navPoint>
+  </navMap>
+</ncx>`),
+		"OEBPS/ch1.xhtml": mustEncode(t, charmap.Windows1252, `<?xml version="1.0" encoding="windows-1252"?>
+<html><body><p>Déjà vu — “quoted”</p></body></html>`),
+	})
+
+	if got := book.Metadata().Titles; len(got) != 1 || got[0] != "Café" {
+		t.Errorf("Titles = %v, want [Café]", got)
+	}
+	if toc := book.TOC(); len(toc) != 1 || toc[0].Title != "Première" {
+		t.Errorf("TOC() = %+v, want one entry titled Première", toc)
+	}
+	text, err := book.Chapters()[0].TextContent()
+	if err != nil {
+		t.Fatalf("TextContent() error = %v", err)
+	}
+	if text != "Déjà vu — “quoted”" {
+		t.Errorf("TextContent() = %q", text)
+	}
+
+	for _, want := range []string{
+		"OEBPS/content.opf: transcoded from utf-16be to UTF-8",
+		"OEBPS/toc.ncx: transcoded from windows-1252 to UTF-8",
+		"OEBPS/ch1.xhtml: transcoded from windows-1252 to UTF-8",
+	} {
+		if !containsWarning(book.Warnings(), want) {
+			t.Errorf("Warnings() = %v, want %q", book.Warnings(), want)
+		}
+	}
+	// A second read does not repeat the warning.
+	if _, err := book.Chapters()[0].TextContent(); err != nil {
+		t.Fatalf("TextContent() error = %v", err)
+	}
+	n := 0
+	for _, w := range book.Warnings() {
+		if strings.HasPrefix(w, "OEBPS/ch1.xhtml:") {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("chapter transcoding warnings = %d, want 1", n)
+	}
+}
+
+func TestBook_UTF8DeclaredMultiByte(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:mislabel</dc:identifier>
+    <dc:title>Mislabel</dc:title>
+  </metadata>
+  <manifest><item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/></manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`,
+		"OEBPS/ch1.xhtml": `<?xml version="1.0" encoding="gb2312"?>
+<html><body><p>中文。</p></body></html>`,
+	})
+
+	text, err := book.Chapters()[0].TextContent()
+	if err != nil {
+		t.Fatalf("TextContent() error = %v", err)
+	}
+	if text != "中文。" {
+		t.Errorf("TextContent() = %q, want %q", text, "中文。")
+	}
+	if want := "OEBPS/ch1.xhtml: declared as gb2312 but valid UTF-8"; !containsWarning(book.Warnings(), want) {
+		t.Errorf("Warnings() = %v, want %q", book.Warnings(), want)
+	}
+}
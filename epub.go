@@ -28,11 +28,33 @@ type Book struct {
 	spine           []spineItem
 	guide           []guideReference
 	metadata        Metadata
+	rendition       Rendition
+	displayOptions  DisplayOptions
 	toc             []TOCItem
 	landmarks       []TOCItem
 	chapters        []Chapter
 	warnings        []string
 	licenseDetected bool
+	pageList        []PageTarget
+	pageListBuilt   bool
+	pagesDeclared   bool // page list from the nav document or NCX
+	navLists        []NavList
+	navListsBuilt   bool
+	tocSynthesized  bool
+	tocAnchors      map[string]map[int]string // ids inserted into headings by SynthesizeTOC
+	tocIndex        *TOCIndex
+	semantics       []StructuralSemantic
+	semanticsBuilt  bool
+	rolesClassified bool
+
+	detectors           []BoilerplateDetector
+	detectorsSet        bool
+	boilerplateDetected bool
+
+	notes      []Note
+	notesBuilt bool
+
+	encodingWarned map[string]bool // files already reported by transcode
 }
 
 // Open opens an ePub file at the given path.
@@ -78,7 +100,7 @@ func initBook(zr *zip.Reader, closer io.Closer) (*Book, error) {
 	b.validateMimetype()
 
 	// Parse container to find OPF path.
-	opfPath, err := parseContainer(zr)
+	opfPath, err := b.parseContainer()
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +125,7 @@ func initBook(zr *zip.Reader, closer io.Closer) (*Book, error) {
 	if err != nil {
 		return nil, fmt.Errorf("epub: read OPF file: %w", err)
 	}
+	opfData = b.transcode(opfPath, opfData)
 
 	pkg, err := parseOPF(opfData)
 	if err != nil {
@@ -113,6 +136,8 @@ func initBook(zr *zip.Reader, closer io.Closer) (*Book, error) {
 	b.spine = buildSpine(pkg.Spine, b.manifestByID)
 	b.guide = buildGuide(pkg.Guide)
 	b.metadata = extractMetadata(pkg)
+	b.rendition = extractRendition(pkg)
+	b.parseDisplayOptions()
 
 	// Parse TOC (nav document or NCX). Errors are non-fatal;
 	// a missing TOC results in an empty slice.
@@ -168,8 +193,17 @@ func (b *Book) ReadFile(name string) ([]byte, error) {
 }
 
 // readFile implements the bookReader interface for lazy content loading.
+// The content is transcoded to UTF-8, and heading ids generated by
+// SynthesizeTOC are inserted.
 func (b *Book) readFile(name string) ([]byte, error) {
-	return b.ReadFile(name)
+	data, err := b.readContent(name)
+	if err != nil {
+		return nil, err
+	}
+	if ids := b.tocAnchors[name]; len(ids) > 0 {
+		data = insertHeadingIDs(data, ids)
+	}
+	return data, nil
 }
 
 // buildZipIndex builds exact-match and lowercase ZIP file indexes for O(1) lookups.
@@ -246,7 +280,8 @@ func (b *Book) Landmarks() []TOCItem {
 //
 // Note: IsLicense is not populated by Chapters(). Call ContentChapters() to
 // trigger Gutenberg license detection; after that call, the cached chapters
-// returned by Chapters() will also have IsLicense set.
+// returned by Chapters() will also have IsLicense set. Role is populated the
+// same way by BodyChapters() or ClassifyChapters().
 func (b *Book) Chapters() []Chapter {
 	if b.chapters != nil {
 		return copyChapters(b.chapters)
@@ -260,12 +295,17 @@ func (b *Book) Chapters() []Chapter {
 		href := b.resolveOPFPath(si.Href)
 
 		ch := Chapter{
-			ID:     si.ID,
-			Href:   href,
-			Title:  tocTitleMap[href],
-			Linear: si.Linear,
-			book:   b,
+			ID:         si.ID,
+			Href:       href,
+			Title:      tocTitleMap[href],
+			Linear:     si.Linear,
+			Properties: strings.Fields(si.Properties),
+			book:       b,
 		}
+		if ch.Title != "" {
+			ch.TitleSource = TitleSourceTOC
+		}
+		ch.Rendition, ch.PageSpread = applyItemRefProperties(b.rendition, ch.Properties)
 
 		chapters = append(chapters, ch)
 	}
@@ -275,31 +315,41 @@ func (b *Book) Chapters() []Chapter {
 }
 
 // ContentChapters returns the chapters in spine order, excluding any
-// detected Project Gutenberg license pages (IsLicense == true).
-// On the first call, it reads every chapter file to perform license
-// detection; subsequent calls use the cached result. After this call,
-// Chapters() also returns chapters with IsLicense correctly set.
+// detected Project Gutenberg license pages (IsLicense == true) and chapters
+// that a registered BoilerplateDetector reports as boilerplate as a whole
+// (IsBoilerplate == true). On the first call, it reads every chapter file to
+// perform detection; subsequent calls use the cached result. After this
+// call, Chapters() also returns chapters with IsLicense and IsBoilerplate
+// correctly set.
 func (b *Book) ContentChapters() []Chapter {
 	b.detectLicenses()
+	b.detectBoilerplate()
 	out := make([]Chapter, 0, len(b.chapters))
 	for _, ch := range b.chapters {
-		if !ch.IsLicense {
+		if !ch.IsLicense && !ch.IsBoilerplate {
 			out = append(out, ch)
 		}
 	}
 	return out
 }
 
-// detectLicenses reads each chapter file and marks Gutenberg license pages.
-// It runs at most once per Book instance.
+// detectLicenses reads each chapter file and marks Gutenberg license pages:
+// chapters the built-in gutenberg detector reports as boilerplate as a
+// whole. A chapter holding the ebook header or footer next to the text is
+// not a license page; CleanTextContent strips those parts. It runs at most
+// once per Book instance.
 func (b *Book) detectLicenses() {
 	if b.licenseDetected {
 		return
 	}
 	_ = b.Chapters() // ensure chapters are built
 	for i := range b.chapters {
-		if raw, err := b.readFile(b.chapters[i].Href); err == nil {
-			b.chapters[i].IsLicense = isGutenbergLicense(raw)
+		raw, err := b.readFile(b.chapters[i].Href)
+		if err != nil {
+			continue
+		}
+		if doc, _, err := newBoilerplateDocument(stripBOM(raw), b.chapters[i].Href, i); err == nil {
+			b.chapters[i].IsLicense = gutenbergDetector{}.Detect(doc).Whole
 		}
 	}
 	b.licenseDetected = true
@@ -326,10 +376,65 @@ func buildTOCTitleMap(items []TOCItem) map[string]string {
 func copyMetadata(in Metadata) Metadata {
 	out := in
 	out.Titles = append([]string(nil), in.Titles...)
-	out.Authors = append([]Author(nil), in.Authors...)
+	out.TitleDetails = copyTitles(in.TitleDetails)
+	out.Authors = copyAuthors(in.Authors)
 	out.Language = append([]string(nil), in.Language...)
 	out.Identifiers = append([]Identifier(nil), in.Identifiers...)
 	out.Subjects = append([]string(nil), in.Subjects...)
+	out.Dates = append([]EventDate(nil), in.Dates...)
+	out.Collections = copyCollections(in.Collections)
+	out.Accessibility = copyAccessibility(in.Accessibility)
+	return out
+}
+
+func copyTitles(in []Title) []Title {
+	if in == nil {
+		return nil
+	}
+	out := make([]Title, len(in))
+	for i := range in {
+		out[i] = in[i]
+		out[i].AlternateScripts = append([]AlternateScript(nil), in[i].AlternateScripts...)
+	}
+	return out
+}
+
+func copyAuthors(in []Author) []Author {
+	if in == nil {
+		return nil
+	}
+	out := make([]Author, len(in))
+	for i := range in {
+		out[i] = in[i]
+		out[i].AlternateScripts = append([]AlternateScript(nil), in[i].AlternateScripts...)
+	}
+	return out
+}
+
+func copyCollections(in []Collection) []Collection {
+	if in == nil {
+		return nil
+	}
+	out := make([]Collection, len(in))
+	for i := range in {
+		out[i] = in[i]
+		out[i].Collections = copyCollections(in[i].Collections)
+	}
+	return out
+}
+
+func copyAccessibility(in Accessibility) Accessibility {
+	out := in
+	out.AccessModes = append([]string(nil), in.AccessModes...)
+	if in.AccessModesSufficient != nil {
+		out.AccessModesSufficient = make([][]string, len(in.AccessModesSufficient))
+		for i, set := range in.AccessModesSufficient {
+			out.AccessModesSufficient[i] = append([]string(nil), set...)
+		}
+	}
+	out.Features = append([]string(nil), in.Features...)
+	out.Hazards = append([]string(nil), in.Hazards...)
+	out.ConformsTo = append([]string(nil), in.ConformsTo...)
 	return out
 }
 
@@ -345,9 +450,25 @@ func copyTOCItems(in []TOCItem) []TOCItem {
 	return out
 }
 
+func copyNavLists(in []NavList) []NavList {
+	if in == nil {
+		return nil
+	}
+	out := make([]NavList, len(in))
+	for i := range in {
+		out[i] = in[i]
+		out[i].Items = copyTOCItems(in[i].Items)
+	}
+	return out
+}
+
 func copyChapters(in []Chapter) []Chapter {
 	if in == nil {
 		return nil
 	}
-	return append([]Chapter(nil), in...)
+	out := append([]Chapter(nil), in...)
+	for i := range out {
+		out[i].Properties = append([]string(nil), in[i].Properties...)
+	}
+	return out
 }
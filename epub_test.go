@@ -108,27 +108,46 @@ func TestWarnings_DefensiveCopy(t *testing.T) {
 
 func TestMetadata_DefensiveCopy(t *testing.T) {
 	book := &Book{metadata: Metadata{
-		Version:     "3.0",
-		Titles:      []string{"Original Title"},
-		Authors:     []Author{{Name: "Author A"}},
+		Version: "3.0",
+		Titles:  []string{"Original Title"},
+		TitleDetails: []Title{{
+			Value:            "Original Title",
+			AlternateScripts: []AlternateScript{{Value: "Alt Title"}},
+		}},
+		Authors: []Author{{
+			Name:             "Author A",
+			AlternateScripts: []AlternateScript{{Value: "Alt Author"}},
+		}},
 		Language:    []string{"en"},
 		Identifiers: []Identifier{{Value: "id-1"}},
 		Subjects:    []string{"Fiction"},
+		Collections: []Collection{{
+			Name:        "Series",
+			Collections: []Collection{{Name: "Parent"}},
+		}},
 	}}
 
 	md := book.Metadata()
 	md.Titles[0] = "Mutated"
 	md.Authors[0].Name = "Mutated Author"
+	md.TitleDetails[0].AlternateScripts[0].Value = "Mutated Alt"
+	md.Authors[0].AlternateScripts[0].Value = "Mutated Alt"
 	md.Language[0] = "fr"
 	md.Identifiers[0].Value = "changed"
 	md.Subjects[0] = "Changed"
+	md.Collections[0].Name = "Mutated Series"
+	md.Collections[0].Collections[0].Name = "Mutated Parent"
 
 	again := book.Metadata()
 	if again.Titles[0] != "Original Title" ||
 		again.Authors[0].Name != "Author A" ||
+		again.TitleDetails[0].AlternateScripts[0].Value != "Alt Title" ||
+		again.Authors[0].AlternateScripts[0].Value != "Alt Author" ||
 		again.Language[0] != "en" ||
 		again.Identifiers[0].Value != "id-1" ||
-		again.Subjects[0] != "Fiction" {
+		again.Subjects[0] != "Fiction" ||
+		again.Collections[0].Name != "Series" ||
+		again.Collections[0].Collections[0].Name != "Parent" {
 		t.Fatalf("Metadata() exposed internal state: %#v", again)
 	}
 }
@@ -23,4 +23,8 @@ var (
 	// ErrNoCover indicates no cover image could be detected
 	// using any of the supported strategies.
 	ErrNoCover = errors.New("epub: no cover image found")
+
+	// ErrNotInSpine indicates a TOC entry or href does not point to a
+	// document in the spine.
+	ErrNotInSpine = errors.New("epub: target is not in the spine")
 )
@@ -2,4 +2,7 @@ module github.com/simp-lee/epub
 
 go 1.24.0
 
-require golang.org/x/net v0.50.0
+require (
+	golang.org/x/net v0.50.0
+	golang.org/x/text v0.34.0
+)
@@ -3,6 +3,7 @@ package epub
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"net/url"
 	"regexp"
@@ -12,54 +13,51 @@ import (
 	"golang.org/x/net/html/atom"
 )
 
-// entityNameToNumeric maps lowercase HTML entity names to their XML numeric
-// character references. encoding/xml does not recognise HTML named entities,
-// so we convert them before parsing OPF/NCX files.
-var entityNameToNumeric = map[string][]byte{
-	"nbsp": []byte("&#160;"), "mdash": []byte("&#8212;"), "ndash": []byte("&#8211;"),
-	"hellip": []byte("&#8230;"),
-	"lsquo": []byte("&#8216;"), "rsquo": []byte("&#8217;"),
-	"ldquo": []byte("&#8220;"), "rdquo": []byte("&#8221;"),
-	"copy": []byte("&#169;"), "reg": []byte("&#174;"), "trade": []byte("&#8482;"),
-	"bull": []byte("&#8226;"), "middot": []byte("&#183;"),
-	"eacute": []byte("&#233;"), "egrave": []byte("&#232;"),
-	"ecirc": []byte("&#234;"), "euml": []byte("&#235;"),
-	"aacute": []byte("&#225;"), "agrave": []byte("&#224;"),
-	"acirc": []byte("&#226;"), "auml": []byte("&#228;"),
-	"iacute": []byte("&#237;"), "igrave": []byte("&#236;"),
-	"icirc": []byte("&#238;"), "iuml": []byte("&#239;"),
-	"oacute": []byte("&#243;"), "ograve": []byte("&#242;"),
-	"ocirc": []byte("&#244;"), "ouml": []byte("&#246;"),
-	"uacute": []byte("&#250;"), "ugrave": []byte("&#249;"),
-	"ucirc": []byte("&#251;"), "uuml": []byte("&#252;"),
-	"ntilde": []byte("&#241;"), "ccedil": []byte("&#231;"),
-	"times": []byte("&#215;"), "divide": []byte("&#247;"),
-	"deg": []byte("&#176;"), "para": []byte("&#182;"), "sect": []byte("&#167;"),
-	"laquo": []byte("&#171;"), "raquo": []byte("&#187;"),
-	"iexcl": []byte("&#161;"), "iquest": []byte("&#191;"),
-}
+// xmlEntityRefPattern matches named entity references (&name;).
+var xmlEntityRefPattern = regexp.MustCompile(`&([A-Za-z_:][-A-Za-z0-9._:]*);`)
 
-// htmlEntityPattern matches common HTML named entities case-insensitively.
-var htmlEntityPattern = regexp.MustCompile(
-	`(?i)&(nbsp|mdash|ndash|hellip|lsquo|rsquo|ldquo|rdquo|copy|reg|trade|bull|middot|` +
-		`eacute|egrave|ecirc|euml|aacute|agrave|acirc|auml|iacute|igrave|icirc|iuml|` +
-		`oacute|ograve|ocirc|ouml|uacute|ugrave|ucirc|uuml|ntilde|ccedil|` +
-		`times|divide|deg|para|sect|laquo|raquo|iexcl|iquest);`)
+// xmlPredefinedEntities are the entities encoding/xml understands natively.
+var xmlPredefinedEntities = map[string]bool{
+	"amp": true, "lt": true, "gt": true, "quot": true, "apos": true,
+}
 
-// preprocessHTMLEntities replaces common HTML named entities with their
-// numeric character references so that encoding/xml can parse the data.
-// The matching is case-insensitive to handle non-standard ePub content.
+// preprocessHTMLEntities replaces HTML named entities (the full HTML5 table)
+// with their numeric character references so that encoding/xml can parse the
+// data. The five XML entities are preserved. Names that are not HTML
+// entities are also tried in lower case to handle non-standard ePub content.
 func preprocessHTMLEntities(data []byte) []byte {
-	return htmlEntityPattern.ReplaceAllFunc(data, func(match []byte) []byte {
-		// Extract entity name between & and ;, lowercase for lookup.
-		name := strings.ToLower(string(match[1 : len(match)-1]))
-		if replacement, ok := entityNameToNumeric[name]; ok {
-			return replacement
+	return xmlEntityRefPattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		name := string(match[1 : len(match)-1])
+		if xmlPredefinedEntities[name] {
+			return match
+		}
+		if ref, ok := htmlEntityToNumeric(name); ok {
+			return ref
 		}
 		return match
 	})
 }
 
+// htmlEntityToNumeric returns the numeric character reference(s) for the
+// HTML named entity name, trying its lower-case form as a fallback.
+func htmlEntityToNumeric(name string) ([]byte, bool) {
+	for _, n := range []string{name, strings.ToLower(name)} {
+		ref := "&" + n + ";"
+		text := html.UnescapeString(ref)
+		// A result still ending in ";" means only a prefix of the name was
+		// a (legacy, semicolon-less) entity, e.g. "&notit;" → "¬it;".
+		if text == ref || (strings.HasSuffix(text, ";") && n != "semi") {
+			continue
+		}
+		var buf []byte
+		for _, r := range text {
+			buf = fmt.Appendf(buf, "&#%d;", r)
+		}
+		return buf, true
+	}
+	return nil, false
+}
+
 // blockTags is the set of tags that should insert a newline when encountered
 // during text extraction.
 var blockTags = map[atom.Atom]bool{
@@ -71,6 +71,26 @@ func TestPreprocessHTMLEntities_Dashes(t *testing.T) {
 	}
 }
 
+func TestPreprocessHTMLEntities_FullHTML5Table(t *testing.T) {
+	input := []byte(`&alpha;&Omega; &rarr; &euro; &Auml;&ouml; &Ccaron; &NotEqualTilde;`)
+	got := preprocessHTMLEntities(input)
+	want := `&#945;&#937; &#8594; &#8364; &#196;&#246; &#268; &#8770;&#824;`
+	if string(got) != want {
+		t.Errorf("preprocessHTMLEntities():\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestPreprocessHTMLEntities_UnknownAndCaseFallback(t *testing.T) {
+	// Unknown names are left for the XML decoder; a legacy entity prefix
+	// ("&not" in "&notit;") must not be treated as a match.
+	input := []byte(`&MDASH; &notit; &bogus;`)
+	got := preprocessHTMLEntities(input)
+	want := `&#8212; &notit; &bogus;`
+	if string(got) != want {
+		t.Errorf("preprocessHTMLEntities():\n got: %s\nwant: %s", got, want)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // extractText tests
 // ---------------------------------------------------------------------------
@@ -0,0 +1,525 @@
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Markdown converts this chapter's <body> to GitHub Flavored Markdown.
+// Headings, emphasis, links, lists, tables, block quotes, code, and images
+// are converted; other markup is reduced to its text. Image paths are
+// resolved to ZIP-internal paths as in BodyHTML. Links to spine documents
+// are rewritten to in-page anchors (see Book.WriteMarkdown), and elements
+// with an id get a matching <a id="..."></a> anchor. Note references (see
+// Book.Notes) become footnotes ([^n], numbered by position in Book.Notes)
+// whose definitions follow the chapter text; note bodies are removed.
+func (c Chapter) Markdown() (string, error) {
+	data, err := c.RawContent()
+	if err != nil {
+		return "", err
+	}
+	data = rewriteImagePaths(normalizeSelfClosingSkipTags(data), c.Href)
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	notes := c.book.bookNotes()
+	var defs []string
+	matchNoteReferences(doc, c.Href, notes, func(a *html.Node, i int) {
+		ref := "[^" + strconv.Itoa(i+1) + "]"
+		replaceNoteAnchor(a, &html.Node{Type: html.RawNode, Data: ref})
+		defs = append(defs, ref+": "+strings.Join(strings.Fields(notes[i].Text), " "))
+	})
+	removeNotes(doc, c.Href, notes)
+
+	root := findElement(doc, atom.Body)
+	if root == nil {
+		root = doc
+	}
+	w := &markdownWriter{book: c.book, file: c.Href, spineIndex: c.spineIndex()}
+	blocks := append(w.blocks(root), defs...)
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+// WriteMarkdown writes the content chapters (see ContentChapters) to w as
+// one Markdown document. Each chapter is preceded by an anchor
+// <a id="chapter-N"></a>, N being its 1-based spine position, so links
+// between chapters resolve within the document.
+func (b *Book) WriteMarkdown(w io.Writer) error {
+	for i, ch := range b.ContentChapters() {
+		md, err := ch.Markdown()
+		if err != nil {
+			return fmt.Errorf("epub: convert %s to Markdown: %w", ch.Href, err)
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n\n"); err != nil {
+				return err
+			}
+		}
+		anchor := `<a id="` + chapterAnchor(ch.spineIndex()) + `"></a>`
+		if md != "" {
+			anchor += "\n\n"
+		}
+		if _, err := io.WriteString(w, anchor+md); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// chapterAnchor returns the anchor name of the spine document at index i.
+func chapterAnchor(i int) string {
+	return "chapter-" + strconv.Itoa(i+1)
+}
+
+// fragmentAnchor returns the anchor name of the element with the given id
+// in the spine document at index i. Prefixing the chapter keeps ids unique
+// when chapters are concatenated.
+func fragmentAnchor(i int, id string) string {
+	return chapterAnchor(i) + "-" + id
+}
+
+// internalLinkTarget resolves href, found in the document at file, to an
+// in-page anchor (with "#") when it points into a spine document. Other
+// relative links are resolved to ZIP-internal paths; absolute URIs are
+// returned unchanged.
+func internalLinkTarget(b bookReader, file, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || hasURIScheme(href) || strings.HasPrefix(href, "//") {
+		return href
+	}
+	p, frag, _ := strings.Cut(href, "#")
+	target := file
+	if p != "" {
+		target = resolveRelativePath(file, p)
+		if target == "" {
+			return href
+		}
+	}
+	i := b.spineIndexOf(target)
+	switch {
+	case i < 0 && frag != "":
+		return target + "#" + frag
+	case i < 0:
+		return target
+	case frag == "":
+		return "#" + chapterAnchor(i)
+	default:
+		return "#" + fragmentAnchor(i, frag)
+	}
+}
+
+// markdownWriter converts a DOM subtree of the document at file to
+// Markdown.
+type markdownWriter struct {
+	book       bookReader
+	file       string
+	spineIndex int
+}
+
+// markdownEscaper escapes characters with inline meaning in Markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `|`, `\|`,
+)
+
+// markdownLineStartPattern matches text at the start of a line that would
+// be read as a heading, list item, block quote, or setext underline.
+var markdownLineStartPattern = regexp.MustCompile(`(?m)^(>|(#{1,6}|[-+]|\d+[.)]|=+)(\s|$))`)
+
+// spaceRunPattern matches runs of spaces left by adjacent inline elements.
+var spaceRunPattern = regexp.MustCompile(` {2,}`)
+
+// blocks converts the children of n to Markdown blocks. Consecutive inline
+// nodes form a paragraph.
+func (w *markdownWriter) blocks(n *html.Node) []string {
+	var out []string
+	var run []*html.Node
+	flush := func() {
+		if p := w.paragraph(run...); p != "" {
+			out = append(out, p)
+		}
+		run = nil
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (isBlockElement(c) || containsBlockElement(c)) {
+			flush()
+			for _, b := range w.block(c) {
+				if b != "" {
+					out = append(out, b)
+				}
+			}
+			continue
+		}
+		run = append(run, c)
+	}
+	flush()
+	return out
+}
+
+// block converts a block element to Markdown blocks.
+func (w *markdownWriter) block(n *html.Node) []string {
+	anchor := w.anchor(n)
+	var out []string
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.ReplaceAll(w.inline(n.FirstChild, nil), "\\\n", " ")
+		text = strings.TrimSpace(spaceRunPattern.ReplaceAllString(text, " "))
+		if text == "" && anchor == "" {
+			return nil
+		}
+		return []string{strings.TrimSpace(strings.Repeat("#", headingLevel(n.DataAtom)) + " " + anchor + text)}
+	case atom.P, atom.Dt, atom.Dd, atom.Figcaption, atom.Caption, atom.Address:
+		if !containsBlockElement(n) {
+			if p := w.paragraph(childNodes(n)...); p != "" {
+				return []string{anchor + p}
+			}
+			return nil
+		}
+		out = w.blocks(n)
+	case atom.Hr:
+		return []string{"---"}
+	case atom.Pre:
+		out = []string{w.code(n)}
+	case atom.Blockquote:
+		quoted := strings.Split(strings.Join(w.blocks(n), "\n\n"), "\n")
+		for i, line := range quoted {
+			quoted[i] = strings.TrimRight("> "+line, " ")
+		}
+		out = []string{strings.Join(quoted, "\n")}
+	case atom.Ul, atom.Ol:
+		out = []string{w.list(n)}
+	case atom.Table:
+		out = []string{w.table(n)}
+	default:
+		out = w.blocks(n)
+	}
+	if anchor != "" {
+		out = append([]string{strings.TrimSuffix(anchor, " ")}, out...)
+	}
+	return out
+}
+
+// anchor returns an <a id="..."></a> anchor (followed by a space) for an
+// element with an id, or "".
+func (w *markdownWriter) anchor(n *html.Node) string {
+	id := strings.TrimSpace(navGetAttr(n, "id"))
+	if id == "" || w.spineIndex < 0 {
+		return ""
+	}
+	return `<a id="` + html.EscapeString(fragmentAnchor(w.spineIndex, id)) + `"></a> `
+}
+
+// paragraph converts inline nodes to a paragraph.
+func (w *markdownWriter) paragraph(nodes ...*html.Node) string {
+	var buf strings.Builder
+	for _, n := range nodes {
+		buf.WriteString(w.inline(n, n.NextSibling))
+	}
+	lines := strings.Split(spaceRunPattern.ReplaceAllString(buf.String(), " "), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimLeft(line, " ")
+	}
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+	// Drop trailing hard line breaks: an odd number of trailing backslashes
+	// (an even number are escaped backslashes).
+	for (len(text)-len(strings.TrimRight(text, "\\")))%2 == 1 {
+		text = strings.TrimSpace(text[:len(text)-1])
+	}
+	return markdownLineStartPattern.ReplaceAllStringFunc(text, func(s string) string {
+		if i := strings.IndexAny(s, ".)"); i > 0 && s[0] >= '0' && s[0] <= '9' {
+			return s[:i] + `\` + s[i:]
+		}
+		return `\` + s
+	})
+}
+
+// childNodes returns the children of n.
+func childNodes(n *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		nodes = append(nodes, c)
+	}
+	return nodes
+}
+
+// inline converts the sibling nodes from first up to (not including) stop
+// to inline Markdown.
+func (w *markdownWriter) inline(first, stop *html.Node) string {
+	var buf strings.Builder
+	for n := first; n != nil && n != stop; n = n.NextSibling {
+		switch n.Type {
+		case html.TextNode:
+			text := collapseWhitespace(n.Data)
+			if text == "" && n.Data != "" {
+				// Whitespace between inline elements.
+				text = " "
+			}
+			buf.WriteString(markdownEscaper.Replace(text))
+		case html.RawNode:
+			buf.WriteString(n.Data)
+		case html.ElementNode:
+			buf.WriteString(w.inlineElement(n))
+		}
+	}
+	return buf.String()
+}
+
+// inlineElement converts an inline element to Markdown.
+func (w *markdownWriter) inlineElement(n *html.Node) string {
+	if skipTags[n.DataAtom] || (n.Namespace == "svg" && (n.Data == "title" || n.Data == "desc")) {
+		return ""
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		return "\\\n"
+	case atom.Img, atom.Image:
+		src, alt := imageSource(n)
+		if src == "" {
+			return ""
+		}
+		return "![" + markdownEscaper.Replace(collapseWhitespace(alt)) + "](" + markdownURL(src) + ")"
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		return codeSpan(nodeTextContent(n))
+	}
+
+	anchor := w.anchor(n)
+	text := w.inline(n.FirstChild, nil)
+	switch n.DataAtom {
+	case atom.Em, atom.I, atom.Cite, atom.Dfn, atom.Var:
+		text = wrapInline(text, "*")
+	case atom.Strong, atom.B:
+		text = wrapInline(text, "**")
+	case atom.Del, atom.S, atom.Strike:
+		text = wrapInline(text, "~~")
+	case atom.Sup, atom.Sub:
+		if strings.TrimSpace(text) != "" {
+			text = "<" + n.Data + ">" + text + "</" + n.Data + ">"
+		}
+	case atom.A:
+		href := navGetAttr(n, "href")
+		if href != "" && strings.TrimSpace(text) != "" {
+			text = "[" + strings.TrimSpace(text) + "](" + markdownURL(internalLinkTarget(w.book, w.file, href)) + ")"
+		}
+	}
+	return anchor + text
+}
+
+// wrapInline wraps text in the emphasis delimiter d, keeping surrounding
+// whitespace outside the delimiters.
+func wrapInline(text, d string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	i := strings.Index(text, trimmed)
+	return text[:i] + d + trimmed + d + text[i+len(trimmed):]
+}
+
+// codeSpan returns s as an inline code span, using a backtick fence longer
+// than any backtick run in s.
+func codeSpan(s string) string {
+	s = collapseWhitespace(s)
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+	fence := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// longestRun returns the length of the longest run of c in s.
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+// markdownURL formats a link destination, wrapping it in angle brackets
+// when it contains spaces or parentheses.
+func markdownURL(u string) string {
+	if strings.ContainsAny(u, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(u) + ">"
+	}
+	return u
+}
+
+// code converts a <pre> element to a fenced code block. A "language-*" or
+// "lang-*" class on the <pre> or a sole <code> child sets the info string.
+func (w *markdownWriter) code(pre *html.Node) string {
+	text := strings.TrimSuffix(strings.TrimPrefix(nodeTextContent(pre), "\n"), "\n")
+	lang := codeLanguage(pre)
+	if c := pre.FirstChild; lang == "" && c != nil && c.NextSibling == nil && c.DataAtom == atom.Code {
+		lang = codeLanguage(c)
+	}
+	fence := strings.Repeat("`", max(3, longestRun(text, '`')+1))
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+// codeLanguage returns the language named by a "language-*" or "lang-*"
+// class of n, or "".
+func codeLanguage(n *html.Node) string {
+	for _, class := range strings.Fields(navGetAttr(n, "class")) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if lang, ok := strings.CutPrefix(class, prefix); ok && lang != "" {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// list converts a <ul> or <ol> element to a Markdown list. Numbering
+// follows parseListItems; continuation lines are indented under the
+// marker.
+func (w *markdownWriter) list(list *html.Node) string {
+	var items []*html.Node
+	for c := list.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == atom.Li {
+			items = append(items, c)
+		}
+	}
+	step, number := 1, 1
+	if hasAttr(list, "reversed") {
+		step, number = -1, len(items)
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(navGetAttr(list, "start"))); err == nil {
+		number = v
+	}
+
+	var out []string
+	for _, li := range items {
+		if v, err := strconv.Atoi(strings.TrimSpace(navGetAttr(li, "value"))); err == nil {
+			number = v
+		}
+		marker := "- "
+		if list.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+		}
+		number += step
+
+		body := w.blocks(li)
+		if anchor := w.anchor(li); anchor != "" {
+			if len(body) == 0 {
+				body = []string{""}
+			}
+			body[0] = anchor + body[0]
+		}
+		lines := strings.Split(joinListItemBlocks(body), "\n")
+		indent := strings.Repeat(" ", len(marker))
+		for i, line := range lines {
+			if i > 0 && line != "" {
+				lines[i] = indent + line
+			}
+		}
+		out = append(out, strings.TrimRight(marker+strings.Join(lines, "\n"), " "))
+	}
+	return strings.Join(out, "\n")
+}
+
+// joinListItemBlocks joins the blocks of a list item. A nested list
+// directly follows the preceding block, keeping the list tight.
+func joinListItemBlocks(blocks []string) string {
+	var buf strings.Builder
+	for i, b := range blocks {
+		if i > 0 {
+			if markdownListPattern.MatchString(b) {
+				buf.WriteString("\n")
+			} else {
+				buf.WriteString("\n\n")
+			}
+		}
+		buf.WriteString(b)
+	}
+	return buf.String()
+}
+
+// markdownListPattern matches the first marker of a Markdown list.
+var markdownListPattern = regexp.MustCompile(`^(-|\d+\.) `)
+
+// table converts a <table> element to a GFM table. The first row is the
+// header row; cells are padded to the widest row. A caption becomes a
+// paragraph before the table.
+func (w *markdownWriter) table(table *html.Node) string {
+	var caption string
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Caption:
+				caption = w.paragraph(childNodes(c)...)
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(c)
+			case atom.Tr:
+				var cells []string
+				for td := c.FirstChild; td != nil; td = td.NextSibling {
+					if td.Type == html.ElementNode && (td.DataAtom == atom.Td || td.DataAtom == atom.Th) {
+						cells = append(cells, w.cell(td))
+					}
+				}
+				rows = append(rows, cells)
+			}
+		}
+	}
+	walk(table)
+
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return caption
+	}
+
+	var buf strings.Builder
+	if caption != "" {
+		buf.WriteString(caption + "\n\n")
+	}
+	writeRow := func(cells []string) {
+		buf.WriteString("|")
+		for i := 0; i < cols; i++ {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			buf.WriteString(" " + cell + " |")
+		}
+	}
+	writeRow(rows[0])
+	buf.WriteString("\n|" + strings.Repeat(" --- |", cols))
+	for _, row := range rows[1:] {
+		buf.WriteString("\n")
+		writeRow(row)
+	}
+	return buf.String()
+}
+
+// cell converts a table cell to single-line inline Markdown; line and
+// paragraph breaks become <br>.
+func (w *markdownWriter) cell(td *html.Node) string {
+	text := strings.Join(w.blocks(td), "<br>")
+	text = strings.ReplaceAll(text, "\\\n", "<br>")
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\n\n", "<br>"), "\n", " ")
+}
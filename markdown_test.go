@@ -0,0 +1,122 @@
+package epub
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func openMarkdownTestBook(t *testing.T, body string) *Book {
+	t.Helper()
+	return openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:markdown</dc:identifier>
+    <dc:title>Markdown</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="text/ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="ch1"/><itemref idref="ch2"/></spine>
+</package>`,
+		"OEBPS/text/ch1.xhtml": `<html><body>` + body + `</body></html>`,
+		"OEBPS/text/ch2.xhtml": `<html><body><h1 id="top">Two</h1><p id="end">The end.</p></body></html>`,
+	})
+}
+
+func TestChapter_Markdown(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "headings and emphasis",
+			body: `<h1>Title</h1><h3>Sub <em>title</em></h3><p>Some <em>emphasis</em>, <strong>strong</strong> <b>bold</b> and <code>a*b</code>.</p>`,
+			want: "# Title\n\n### Sub *title*\n\nSome *emphasis*, **strong** **bold** and `a*b`.",
+		},
+		{
+			name: "escaping",
+			body: `<p>1. Not a list, a_b *c* [d]</p><p># Not a heading</p>`,
+			want: "1\\. Not a list, a\\_b \\*c\\* \\[d\\]\n\n\\# Not a heading",
+		},
+		{
+			name: "links",
+			body: `<p><a href="ch2.xhtml">Next</a>, <a href="ch2.xhtml#end">the end</a>, <a href="#here">here</a> and <a href="https://example.com/">web</a>.</p><p id="here">Here.</p>`,
+			want: "[Next](#chapter-2), [the end](#chapter-2-end), [here](#chapter-1-here) and [web](https://example.com/).\n\n<a id=\"chapter-1-here\"></a> Here.",
+		},
+		{
+			name: "lists",
+			body: `<ul><li>One<ol start="3"><li>Three</li><li>Four</li></ol></li><li><p>Two</p><p>More</p></li></ul>`,
+			want: "- One\n  3. Three\n  4. Four\n- Two\n\n  More",
+		},
+		{
+			name: "table",
+			body: `<table><caption>Fruit</caption><thead><tr><th>Name</th><th>Qty</th></tr></thead><tbody><tr><td>A|B</td><td>1<br/>2</td></tr><tr><td>C</td></tr></tbody></table>`,
+			want: "Fruit\n\n| Name | Qty |\n| --- | --- |\n| A\\|B | 1<br>2 |\n| C |  |",
+		},
+		{
+			name: "quote and code",
+			body: "<blockquote><p>Quoted</p><p>Twice</p></blockquote><pre><code class=\"language-go\">x := `a`\n</code></pre>",
+			want: "> Quoted\n>\n> Twice\n\n```go\nx := `a`\n```",
+		},
+		{
+			name: "images and rule",
+			body: `<p><img src="../images/a b.png" alt="An image"/></p><hr/><p>Line<br/>break<br/></p>`,
+			want: "![An image](<OEBPS/images/a b.png>)\n\n---\n\nLine\\\nbreak",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := openMarkdownTestBook(t, tt.body)
+			got, err := book.Chapters()[0].Markdown()
+			if err != nil {
+				t.Fatalf("Markdown() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Markdown() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChapter_Markdown_Footnotes(t *testing.T) {
+	book := openNotesTestBook(t)
+	got, err := book.Chapters()[0].Markdown()
+	if err != nil {
+		t.Fatalf("Markdown() error = %v", err)
+	}
+	want := "Text[^1] and more[^2].\n\nSee [part two](#chapter-2-part).\n\n[^1]: A footnote.\n\n[^2]: An endnote."
+	if got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+
+	notes, err := book.Chapters()[2].Markdown()
+	if err != nil {
+		t.Fatalf("Markdown() error = %v", err)
+	}
+	if notes != "# Notes" {
+		t.Errorf("Markdown() of notes chapter = %q, want %q", notes, "# Notes")
+	}
+}
+
+func TestBook_WriteMarkdown(t *testing.T) {
+	book := openMarkdownTestBook(t, `<h1>One</h1><p>See <a href="ch2.xhtml#top">two</a>.</p>`)
+	var buf bytes.Buffer
+	if err := book.WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	want := strings.Join([]string{
+		`<a id="chapter-1"></a>`,
+		`# One`,
+		`See [two](#chapter-2-top).`,
+		`<a id="chapter-2"></a>`,
+		`# <a id="chapter-2-top"></a> Two`,
+		`<a id="chapter-2-end"></a> The end.`,
+	}, "\n\n") + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package epub
 
 import (
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -17,10 +18,13 @@ func extractMetadata(opf *opfPackage) Metadata {
 	refinesMap := buildRefinesMap(om.Metas)
 
 	// Titles.
-	md.Titles = extractTitles(om.Titles, refinesMap)
+	md.TitleDetails = extractTitles(om.Titles, refinesMap, opf.Lang, opf.Dir)
+	for _, t := range md.TitleDetails {
+		md.Titles = append(md.Titles, t.Value)
+	}
 
 	// Authors (dc:creator).
-	md.Authors = extractAuthors(om.Creators, refinesMap)
+	md.Authors = extractAuthors(om.Creators, refinesMap, opf.Lang, opf.Dir)
 
 	// Languages.
 	for _, l := range om.Languages {
@@ -65,6 +69,9 @@ func extractMetadata(opf *opfPackage) Metadata {
 		}
 	}
 
+	// Typed dates (publication, modification, event-tagged).
+	extractDates(&md, om)
+
 	// Description — take first non-empty.
 	for _, d := range om.Descriptions {
 		if v := strings.TrimSpace(d.Value); v != "" {
@@ -96,6 +103,12 @@ func extractMetadata(opf *opfPackage) Metadata {
 		}
 	}
 
+	// Series and collections.
+	md.Collections = extractCollections(opf, refinesMap)
+
+	// Accessibility (schema.org and conformance metadata).
+	md.Accessibility = extractAccessibility(om)
+
 	return md
 }
 
@@ -127,16 +140,16 @@ func findRefine(refinesMap map[string][]opfMeta, id, property string) (string, b
 	return "", false
 }
 
-// extractTitles extracts titles from dc:title elements.
-// For ePub 3, titles are ordered by display-seq from refines metadata.
-func extractTitles(titles []opfDCElement, refinesMap map[string][]opfMeta) []string {
+// extractTitles extracts titles from dc:title elements together with their
+// ePub 3 refinements. For ePub 3, titles are ordered by display-seq from
+// refines metadata. lang and dir are the package-level defaults.
+func extractTitles(titles []opfDCElement, refinesMap map[string][]opfMeta, lang, dir string) []Title {
 	if len(titles) == 0 {
 		return nil
 	}
 
 	type titleEntry struct {
-		value string
-		seq   int
+		title Title
 		index int // original order
 	}
 
@@ -148,14 +161,32 @@ func extractTitles(titles []opfDCElement, refinesMap map[string][]opfMeta) []str
 		if v == "" {
 			continue
 		}
-		e := titleEntry{value: v, seq: 0, index: i}
+		e := titleEntry{
+			title: Title{
+				Value:    v,
+				Language: firstNonEmpty(t.Lang, lang),
+				Dir:      firstNonEmpty(t.Dir, dir),
+				ID:       t.ID,
+				FileAs:   t.FileAs,
+			},
+			index: i,
+		}
 		if t.ID != "" {
 			if seqStr, ok := findRefine(refinesMap, t.ID, "display-seq"); ok {
 				if n, err := strconv.Atoi(seqStr); err == nil {
-					e.seq = n
+					e.title.DisplaySeq = n
 					hasSeq = true
 				}
 			}
+			if tt, ok := findRefine(refinesMap, t.ID, "title-type"); ok {
+				e.title.Type = strings.ToLower(tt)
+			}
+			if e.title.FileAs == "" {
+				if fa, ok := findRefine(refinesMap, t.ID, "file-as"); ok {
+					e.title.FileAs = fa
+				}
+			}
+			e.title.AlternateScripts = findAlternateScripts(refinesMap, t.ID)
 		}
 		entries = append(entries, e)
 	}
@@ -164,7 +195,7 @@ func extractTitles(titles []opfDCElement, refinesMap map[string][]opfMeta) []str
 	if hasSeq {
 		sort.SliceStable(entries, func(i, j int) bool {
 			// Titles without seq (0) go after titles with seq.
-			si, sj := entries[i].seq, entries[j].seq
+			si, sj := entries[i].title.DisplaySeq, entries[j].title.DisplaySeq
 			if si == 0 && sj == 0 {
 				return entries[i].index < entries[j].index
 			}
@@ -178,17 +209,74 @@ func extractTitles(titles []opfDCElement, refinesMap map[string][]opfMeta) []str
 		})
 	}
 
-	result := make([]string, len(entries))
+	result := make([]Title, len(entries))
 	for i, e := range entries {
-		result[i] = e.value
+		result[i] = e.title
 	}
 	return result
 }
 
+// findAlternateScripts collects the alternate-script refinements of the
+// element with the given ID.
+func findAlternateScripts(refinesMap map[string][]opfMeta, id string) []AlternateScript {
+	var out []AlternateScript
+	for _, m := range refinesMap[id] {
+		if m.Property != "alternate-script" {
+			continue
+		}
+		if v := strings.TrimSpace(m.Value); v != "" {
+			out = append(out, AlternateScript{Value: v, Language: m.Lang, Dir: m.Dir})
+		}
+	}
+	return out
+}
+
+// firstNonEmpty returns the first argument that is not empty after trimming.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// MainTitle returns the title with title-type "main". When no title is
+// typed "main", it returns the first untyped title, and failing that the
+// first title in display order. Returns "" if there are no titles.
+func (m Metadata) MainTitle() string {
+	for _, t := range m.TitleDetails {
+		if t.Type == "main" {
+			return t.Value
+		}
+	}
+	for _, t := range m.TitleDetails {
+		if t.Type == "" {
+			return t.Value
+		}
+	}
+	if len(m.Titles) > 0 {
+		return m.Titles[0]
+	}
+	return ""
+}
+
+// Subtitle returns the first title with title-type "subtitle", or "" if the
+// publication declares none.
+func (m Metadata) Subtitle() string {
+	for _, t := range m.TitleDetails {
+		if t.Type == "subtitle" {
+			return t.Value
+		}
+	}
+	return ""
+}
+
 // extractAuthors extracts author information from dc:creator elements.
 // ePub 2: uses opf:file-as and opf:role attributes directly on the element.
-// ePub 3: uses <meta refines="..."> elements to express file-as and role.
-func extractAuthors(creators []opfDCElement, refinesMap map[string][]opfMeta) []Author {
+// ePub 3: uses <meta refines="..."> elements to express file-as, role, and
+// alternate-script. lang and dir are the package-level defaults.
+func extractAuthors(creators []opfDCElement, refinesMap map[string][]opfMeta, lang, dir string) []Author {
 	if len(creators) == 0 {
 		return nil
 	}
@@ -201,9 +289,11 @@ func extractAuthors(creators []opfDCElement, refinesMap map[string][]opfMeta) []
 		}
 
 		a := Author{
-			Name:   name,
-			FileAs: c.FileAs,
-			Role:   c.Role,
+			Name:     name,
+			FileAs:   c.FileAs,
+			Role:     ParseRole(c.Role),
+			Language: firstNonEmpty(c.Lang, lang),
+			Dir:      firstNonEmpty(c.Dir, dir),
 		}
 
 		// ePub 3: check refines for file-as and role if not set via attributes.
@@ -215,12 +305,224 @@ func extractAuthors(creators []opfDCElement, refinesMap map[string][]opfMeta) []
 			}
 			if a.Role == "" {
 				if r, ok := findRefine(refinesMap, c.ID, "role"); ok {
-					a.Role = r
+					a.Role = ParseRole(r)
 				}
 			}
+			a.AlternateScripts = findAlternateScripts(refinesMap, c.ID)
 		}
 
 		authors = append(authors, a)
 	}
-	return authors
+	return dedupeAuthors(authors)
+}
+
+// extractCollections merges collection membership from all supported sources.
+// Precedence is: ePub 3 belongs-to-collection metas, then Calibre's
+// calibre:series metas, then ePub 3.0.1 <collection> elements. A
+// lower-precedence entry whose name matches an existing entry
+// (case-insensitively) only fills in fields the existing entry lacks.
+func extractCollections(opf *opfPackage, refinesMap map[string][]opfMeta) []Collection {
+	var out []Collection
+
+	// ePub 3: top-level belongs-to-collection metas (refining metas are nested).
+	for _, m := range opf.Metadata.Metas {
+		if m.Property != "belongs-to-collection" || m.Refines != "" {
+			continue
+		}
+		if c, ok := buildMetaCollection(m, refinesMap, map[string]bool{}); ok {
+			out = mergeCollection(out, c)
+		}
+	}
+
+	// Calibre: <meta name="calibre:series" content="..."/>.
+	if c, ok := extractCalibreSeries(opf.Metadata.Metas); ok {
+		out = mergeCollection(out, c)
+	}
+
+	// ePub 3.0.1 <collection> elements.
+	for _, oc := range opf.Collections {
+		if c, ok := convertOPFCollection(oc); ok {
+			out = mergeCollection(out, c)
+		}
+	}
+
+	return out
+}
+
+// buildMetaCollection converts a belongs-to-collection meta and its refines
+// into a Collection. seen guards against refines cycles.
+func buildMetaCollection(m opfMeta, refinesMap map[string][]opfMeta, seen map[string]bool) (Collection, bool) {
+	name := strings.TrimSpace(m.Value)
+	if name == "" {
+		return Collection{}, false
+	}
+
+	c := Collection{Name: name, Position: -1, ID: m.ID}
+	if m.ID == "" || seen[m.ID] {
+		return c, true
+	}
+	seen[m.ID] = true
+
+	for _, r := range refinesMap[m.ID] {
+		v := strings.TrimSpace(r.Value)
+		switch r.Property {
+		case "collection-type":
+			if c.Type == "" {
+				c.Type = v
+			}
+		case "group-position":
+			if c.Position < 0 {
+				c.Position = parseCollectionPosition(v)
+			}
+		case "dcterms:identifier":
+			if c.Identifier == "" {
+				c.Identifier = v
+			}
+		case "file-as":
+			if c.FileAs == "" {
+				c.FileAs = v
+			}
+		case "belongs-to-collection":
+			if parent, ok := buildMetaCollection(r, refinesMap, seen); ok {
+				c.Collections = append(c.Collections, parent)
+			}
+		}
+	}
+	return c, true
+}
+
+// extractCalibreSeries reads the calibre:series and calibre:series_index
+// metas written by Calibre. Both the ePub 2 name/content form and the
+// ePub 3 property form are accepted.
+func extractCalibreSeries(metas []opfMeta) (Collection, bool) {
+	var name, index string
+	for _, m := range metas {
+		key, val := metaKeyValue(m)
+		switch key {
+		case "calibre:series":
+			if name == "" {
+				name = val
+			}
+		case "calibre:series_index":
+			if index == "" {
+				index = val
+			}
+		}
+	}
+	if name == "" {
+		return Collection{}, false
+	}
+	return Collection{
+		Name:     name,
+		Type:     "series",
+		Position: parseCollectionPosition(index),
+	}, true
+}
+
+// metaKeyValue returns the key and trimmed value of a meta element, using
+// the ePub 2 name/content form when present and the ePub 3 property/text
+// form otherwise.
+func metaKeyValue(m opfMeta) (key, value string) {
+	if m.Name != "" {
+		return m.Name, strings.TrimSpace(m.Content)
+	}
+	return m.Property, strings.TrimSpace(m.Value)
+}
+
+// convertOPFCollection converts an ePub 3.0.1 <collection> element into a
+// Collection. The name is taken from the first dc:title (or dcterms:title
+// meta) in the collection's own metadata; unnamed collections are skipped.
+func convertOPFCollection(oc opfCollection) (Collection, bool) {
+	om := &oc.Metadata
+
+	c := Collection{Type: strings.TrimSpace(oc.Role), Position: -1, ID: oc.ID}
+	for _, t := range om.Titles {
+		if v := strings.TrimSpace(t.Value); v != "" {
+			c.Name = v
+			break
+		}
+	}
+	for _, id := range om.Identifiers {
+		if v := strings.TrimSpace(id.Value); v != "" {
+			c.Identifier = v
+			break
+		}
+	}
+	for _, m := range om.Metas {
+		if m.Refines != "" {
+			continue
+		}
+		v := strings.TrimSpace(m.Value)
+		switch m.Property {
+		case "dcterms:title":
+			if c.Name == "" {
+				c.Name = v
+			}
+		case "dcterms:identifier":
+			if c.Identifier == "" {
+				c.Identifier = v
+			}
+		case "group-position":
+			if c.Position < 0 {
+				c.Position = parseCollectionPosition(v)
+			}
+		}
+	}
+
+	for _, child := range oc.Collections {
+		if cc, ok := convertOPFCollection(child); ok {
+			c.Collections = append(c.Collections, cc)
+		}
+	}
+
+	if c.Name == "" {
+		return Collection{}, false
+	}
+	return c, true
+}
+
+// mergeCollection adds c to list. If list already contains a collection with
+// the same name (case-insensitive), the existing entry keeps precedence and
+// only its missing fields are filled from c.
+func mergeCollection(list []Collection, c Collection) []Collection {
+	for i := range list {
+		e := &list[i]
+		if !strings.EqualFold(e.Name, c.Name) {
+			continue
+		}
+		if e.Type == "" {
+			e.Type = c.Type
+		}
+		if e.Position < 0 {
+			e.Position = c.Position
+		}
+		if e.ID == "" {
+			e.ID = c.ID
+		}
+		if e.Identifier == "" {
+			e.Identifier = c.Identifier
+		}
+		if e.FileAs == "" {
+			e.FileAs = c.FileAs
+		}
+		if len(e.Collections) == 0 {
+			e.Collections = c.Collections
+		}
+		return list
+	}
+	return append(list, c)
+}
+
+// parseCollectionPosition parses a group-position or series index value.
+// Returns -1 if the value is empty, malformed, or negative.
+func parseCollectionPosition(s string) float64 {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return -1
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+		return -1
+	}
+	return f
 }
@@ -424,3 +424,290 @@ func TestBookMetadata_V3(t *testing.T) {
 		t.Errorf("Authors[0].FileAs = %q, want %q", md.Authors[0].FileAs, "Doe, John")
 	}
 }
+
+// --- Collections ---
+
+func TestExtractMetadata_BelongsToCollection(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>The Two Towers</dc:title>
+    <meta property="belongs-to-collection" id="c01">The Lord of the Rings</meta>
+    <meta refines="#c01" property="collection-type">series</meta>
+    <meta refines="#c01" property="group-position">2</meta>
+    <meta refines="#c01" property="dcterms:identifier">urn:isbn:9780000000001</meta>
+    <meta refines="#c01" property="belongs-to-collection" id="c02">Middle-earth</meta>
+    <meta refines="#c02" property="collection-type">set</meta>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+	pkg, err := parseOPF([]byte(opf))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	md := extractMetadata(pkg)
+
+	want := []Collection{{
+		Name:       "The Lord of the Rings",
+		Type:       "series",
+		Position:   2,
+		ID:         "c01",
+		Identifier: "urn:isbn:9780000000001",
+		Collections: []Collection{{
+			Name:     "Middle-earth",
+			Type:     "set",
+			Position: -1,
+			ID:       "c02",
+		}},
+	}}
+	if !reflect.DeepEqual(md.Collections, want) {
+		t.Errorf("Collections = %+v, want %+v", md.Collections, want)
+	}
+}
+
+func TestExtractMetadata_CalibreSeries(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+    <dc:title>Book</dc:title>
+    <meta name="calibre:series" content="Discworld"/>
+    <meta name="calibre:series_index" content="2.5"/>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+	pkg, err := parseOPF([]byte(opf))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	md := extractMetadata(pkg)
+
+	want := []Collection{{Name: "Discworld", Type: "series", Position: 2.5}}
+	if !reflect.DeepEqual(md.Collections, want) {
+		t.Errorf("Collections = %+v, want %+v", md.Collections, want)
+	}
+}
+
+func TestExtractMetadata_CollectionPrecedence(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Book</dc:title>
+    <meta property="belongs-to-collection" id="c01">Discworld</meta>
+    <meta name="calibre:series" content="discworld"/>
+    <meta name="calibre:series_index" content="7"/>
+    <meta name="calibre:series_index" content="8"/>
+  </metadata>
+  <manifest/>
+  <spine/>
+  <collection role="series" id="col1">
+    <metadata>
+      <dc:title xmlns:dc="http://purl.org/dc/elements/1.1/">Discworld</dc:title>
+      <meta property="group-position">99</meta>
+    </metadata>
+  </collection>
+  <collection role="anthology">
+    <metadata>
+      <dc:title xmlns:dc="http://purl.org/dc/elements/1.1/">Best of Fantasy</dc:title>
+      <meta property="group-position">3</meta>
+    </metadata>
+    <collection role="volume">
+      <metadata>
+        <meta property="dcterms:title">Volume One</meta>
+      </metadata>
+    </collection>
+    <collection role="unnamed"/>
+  </collection>
+</package>`
+	pkg, err := parseOPF([]byte(opf))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	md := extractMetadata(pkg)
+
+	// The ePub 3 entry wins; Calibre fills the missing type and position
+	// (first index only), and the matching <collection> adds nothing new.
+	want := []Collection{
+		{Name: "Discworld", Type: "series", Position: 7, ID: "c01"},
+		{
+			Name:     "Best of Fantasy",
+			Type:     "anthology",
+			Position: 3,
+			Collections: []Collection{
+				{Name: "Volume One", Type: "volume", Position: -1},
+			},
+		},
+	}
+	if !reflect.DeepEqual(md.Collections, want) {
+		t.Errorf("Collections = %+v, want %+v", md.Collections, want)
+	}
+}
+
+func TestExtractMetadata_CollectionRefinesCycle(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <meta property="belongs-to-collection" id="a">A</meta>
+    <meta refines="#a" property="belongs-to-collection" id="b">B</meta>
+    <meta refines="#b" property="belongs-to-collection" id="a">A again</meta>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+	pkg, err := parseOPF([]byte(opf))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	md := extractMetadata(pkg)
+
+	if len(md.Collections) != 1 || md.Collections[0].Name != "A" {
+		t.Fatalf("Collections = %+v, want single collection A", md.Collections)
+	}
+	b := md.Collections[0].Collections
+	if len(b) != 1 || b[0].Name != "B" {
+		t.Fatalf("A.Collections = %+v, want [B]", b)
+	}
+	if len(b[0].Collections) != 1 || len(b[0].Collections[0].Collections) != 0 {
+		t.Errorf("cycle not cut: B.Collections = %+v", b[0].Collections)
+	}
+}
+
+func TestParseCollectionPosition(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"", -1},
+		{"1", 1},
+		{" 2.50 ", 2.5},
+		{"0", 0},
+		{"abc", -1},
+		{"-3", -1},
+		{"NaN", -1},
+	}
+	for _, tt := range tests {
+		if got := parseCollectionPosition(tt.in); got != tt.want {
+			t.Errorf("parseCollectionPosition(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// --- Title types and alternate scripts ---
+
+const testMetadataOPFMultilingual = `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" xml:lang="ja">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title id="t1">海辺のカフカ</dc:title>
+    <dc:title id="t2">上巻</dc:title>
+    <dc:title id="t3" xml:lang="en" dir="ltr">Kafka on the Shore</dc:title>
+    <meta refines="#t1" property="title-type">main</meta>
+    <meta refines="#t1" property="file-as">ウミベノカフカ</meta>
+    <meta refines="#t1" property="alternate-script" xml:lang="ja-Latn">Umibe no Kafuka</meta>
+    <meta refines="#t2" property="title-type">Subtitle</meta>
+    <meta refines="#t3" property="title-type">expanded</meta>
+    <dc:creator id="c1">村上 春樹</dc:creator>
+    <meta refines="#c1" property="role" scheme="marc:relators">aut</meta>
+    <meta refines="#c1" property="alternate-script" xml:lang="en">Haruki Murakami</meta>
+    <meta refines="#c1" property="alternate-script" xml:lang="zh-Hans">村上春树</meta>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+
+func TestExtractMetadata_TitleTypesAndAlternateScripts(t *testing.T) {
+	pkg, err := parseOPF([]byte(testMetadataOPFMultilingual))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+
+	md := extractMetadata(pkg)
+
+	wantTitles := []Title{
+		{
+			Value:    "海辺のカフカ",
+			Type:     "main",
+			FileAs:   "ウミベノカフカ",
+			Language: "ja",
+			ID:       "t1",
+			AlternateScripts: []AlternateScript{
+				{Value: "Umibe no Kafuka", Language: "ja-Latn"},
+			},
+		},
+		{Value: "上巻", Type: "subtitle", Language: "ja", ID: "t2"},
+		{Value: "Kafka on the Shore", Type: "expanded", Language: "en", Dir: "ltr", ID: "t3"},
+	}
+	if !reflect.DeepEqual(md.TitleDetails, wantTitles) {
+		t.Errorf("TitleDetails = %+v, want %+v", md.TitleDetails, wantTitles)
+	}
+	if want := []string{"海辺のカフカ", "上巻", "Kafka on the Shore"}; !reflect.DeepEqual(md.Titles, want) {
+		t.Errorf("Titles = %v, want %v", md.Titles, want)
+	}
+	if got := md.MainTitle(); got != "海辺のカフカ" {
+		t.Errorf("MainTitle() = %q, want %q", got, "海辺のカフカ")
+	}
+	if got := md.Subtitle(); got != "上巻" {
+		t.Errorf("Subtitle() = %q, want %q", got, "上巻")
+	}
+
+	if len(md.Authors) != 1 {
+		t.Fatalf("Authors count = %d, want 1", len(md.Authors))
+	}
+	a := md.Authors[0]
+	if a.Language != "ja" || a.Role != "aut" {
+		t.Errorf("Authors[0] = %+v, want Language ja and Role aut", a)
+	}
+	wantAlt := []AlternateScript{
+		{Value: "Haruki Murakami", Language: "en"},
+		{Value: "村上春树", Language: "zh-Hans"},
+	}
+	if !reflect.DeepEqual(a.AlternateScripts, wantAlt) {
+		t.Errorf("Authors[0].AlternateScripts = %+v, want %+v", a.AlternateScripts, wantAlt)
+	}
+}
+
+func TestMetadata_MainTitleFallbacks(t *testing.T) {
+	tests := []struct {
+		name         string
+		md           Metadata
+		wantMain     string
+		wantSubtitle string
+	}{
+		{"empty", Metadata{}, "", ""},
+		{
+			"untyped",
+			Metadata{
+				Titles:       []string{"First", "Second"},
+				TitleDetails: []Title{{Value: "First"}, {Value: "Second"}},
+			},
+			"First", "",
+		},
+		{
+			"subtitle first without main",
+			Metadata{
+				Titles:       []string{"Sub", "Plain"},
+				TitleDetails: []Title{{Value: "Sub", Type: "subtitle"}, {Value: "Plain"}},
+			},
+			"Plain", "Sub",
+		},
+		{
+			"titles only",
+			Metadata{Titles: []string{"Legacy"}},
+			"Legacy", "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.md.MainTitle(); got != tt.wantMain {
+				t.Errorf("MainTitle() = %q, want %q", got, tt.wantMain)
+			}
+			if got := tt.md.Subtitle(); got != tt.wantSubtitle {
+				t.Errorf("Subtitle() = %q, want %q", got, tt.wantSubtitle)
+			}
+		})
+	}
+}
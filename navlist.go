@@ -0,0 +1,144 @@
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// NavLists returns every navigation list of the publication, including the
+// main toc, landmarks, page-list, lists of illustrations (loi), tables (lot)
+// and audio (loa), custom navs, and navs marked hidden.
+//
+// For ePub 3 the lists come from the nav document, in document order. When
+// there is no usable nav document, the NCX <navList> elements are returned
+// instead. Spine indices are assigned as for TOC. The result is computed on
+// the first call and cached.
+func (b *Book) NavLists() []NavList {
+	if !b.navListsBuilt {
+		b.navLists = b.buildNavLists()
+		b.navListsBuilt = true
+	}
+	return copyNavLists(b.navLists)
+}
+
+// buildNavLists parses the nav lists from the nav document or, failing that,
+// the NCX.
+func (b *Book) buildNavLists() []NavList {
+	spineMap := b.spineIndexMap()
+	spineLen := len(b.spine)
+
+	if navPath := b.navDocumentPath(); navPath != "" {
+		if data, err := b.readContent(navPath); err == nil {
+			lists, err := parseNavLists(data, navPath)
+			if err != nil {
+				b.warnings = append(b.warnings, fmt.Sprintf("failed to parse nav lists: %v", err))
+			} else if len(lists) > 0 {
+				for i := range lists {
+					assignSpineIndices(lists[i].Items, spineMap)
+					computeSpineRanges(lists[i].Items, spineLen)
+				}
+				return lists
+			}
+		}
+	}
+
+	if ncxPath := b.ncxPath(); ncxPath != "" {
+		if data, err := b.readContent(ncxPath); err == nil {
+			lists, err := parseNCXNavLists(data, ncxPath)
+			if err != nil {
+				b.warnings = append(b.warnings, fmt.Sprintf("failed to parse NCX nav lists: %v", err))
+			} else if len(lists) > 0 {
+				for i := range lists {
+					assignSpineIndices(lists[i].Items, spineMap)
+					computeSpineRanges(lists[i].Items, spineLen)
+				}
+				return lists
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseNavLists parses every <nav> element of an ePub 3 nav document.
+// basePath is the ZIP-internal path of the nav document.
+func parseNavLists(data []byte, basePath string) ([]NavList, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("epub: parse nav document: %w", err)
+	}
+
+	var lists []NavList
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "nav" {
+			list := NavList{
+				Type:   strings.Join(strings.Fields(navGetAttr(n, "epub:type")), " "),
+				Title:  navHeading(n),
+				Hidden: hasAttr(n, "hidden"),
+			}
+			if ol := findFirstChildElement(n, "ol"); ol != nil {
+				list.Items = parseNavOL(ol, basePath)
+			}
+			lists = append(lists, list)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return lists, nil
+}
+
+// navHeading returns the text of the heading (<h1>–<h6>) that labels a nav
+// element, or "" if it has none. Only elements preceding the list are
+// considered.
+func navHeading(nav *html.Node) string {
+	for c := nav.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if c.Data == "ol" {
+			break
+		}
+		if headingLevel(c.DataAtom) > 0 {
+			return strings.TrimSpace(collapseWhitespace(nodeTextContent(c)))
+		}
+	}
+	return ""
+}
+
+// parseNCXNavLists parses the <navList> elements of an NCX document.
+// ncxPath is the ZIP-internal path of the NCX file.
+func parseNCXNavLists(data []byte, ncxPath string) ([]NavList, error) {
+	doc, err := decodeNCX(data)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]NavList, 0, len(doc.NavLists))
+	for _, nl := range doc.NavLists {
+		list := NavList{
+			Type:  strings.TrimSpace(nl.Class),
+			Title: strings.TrimSpace(nl.Label.Text),
+		}
+		for _, nt := range nl.Targets {
+			item := TOCItem{
+				Title:         strings.TrimSpace(nt.Label.Text),
+				SpineIndex:    -1,
+				SpineEndIndex: -1,
+			}
+			if src := strings.TrimSpace(nt.Content.Src); src != "" {
+				item.Href = resolveRelativePath(ncxPath, src)
+			}
+			list.Items = append(list.Items, item)
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
@@ -0,0 +1,149 @@
+package epub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNavLists(t *testing.T) {
+	nav := `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<body>
+  <nav epub:type="toc"><h1>Contents</h1><ol><li><a href="ch1.xhtml">Chapter 1</a></li></ol></nav>
+  <nav epub:type="loi">
+    <h2>List of  Illustrations</h2>
+    <ol>
+      <li><a href="ch1.xhtml#fig1">Figure 1</a></li>
+      <li><a href="ch2.xhtml#fig2">Figure 2</a></li>
+    </ol>
+  </nav>
+  <nav epub:type="lot" hidden="hidden"><ol><li><a href="ch2.xhtml#tab1">Table 1</a></li></ol></nav>
+  <nav><h2>Custom</h2><p>No list here</p></nav>
+</body>
+</html>`
+	got, err := parseNavLists([]byte(nav), "OEBPS/nav.xhtml")
+	if err != nil {
+		t.Fatalf("parseNavLists() error = %v", err)
+	}
+	want := []NavList{
+		{Type: "toc", Title: "Contents", Items: []TOCItem{
+			{Title: "Chapter 1", Href: "OEBPS/ch1.xhtml", SpineIndex: -1, SpineEndIndex: -1},
+		}},
+		{Type: "loi", Title: "List of Illustrations", Items: []TOCItem{
+			{Title: "Figure 1", Href: "OEBPS/ch1.xhtml#fig1", SpineIndex: -1, SpineEndIndex: -1},
+			{Title: "Figure 2", Href: "OEBPS/ch2.xhtml#fig2", SpineIndex: -1, SpineEndIndex: -1},
+		}},
+		{Type: "lot", Hidden: true, Items: []TOCItem{
+			{Title: "Table 1", Href: "OEBPS/ch2.xhtml#tab1", SpineIndex: -1, SpineEndIndex: -1},
+		}},
+		{Title: "Custom"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNavLists() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseNCXNavLists(t *testing.T) {
+	ncx := `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="n1" playOrder="1"><navLabel><text>One</text></navLabel><content src="ch1.xhtml"/></navPoint>
+  </navMap>
+  <navList class="lot">
+    <navLabel><text>Tables</text></navLabel>
+    <navTarget id="t1" playOrder="2"><navLabel><text>Table 1</text></navLabel><content src="ch1.xhtml#t1"/></navTarget>
+    <navTarget id="t2" playOrder="3"><navLabel><text>Table 2</text></navLabel><content src="text/ch2.xhtml#t2"/></navTarget>
+  </navList>
+</ncx>`
+	got, err := parseNCXNavLists([]byte(ncx), "OEBPS/toc.ncx")
+	if err != nil {
+		t.Fatalf("parseNCXNavLists() error = %v", err)
+	}
+	want := []NavList{{Type: "lot", Title: "Tables", Items: []TOCItem{
+		{Title: "Table 1", Href: "OEBPS/ch1.xhtml#t1", SpineIndex: -1, SpineEndIndex: -1},
+		{Title: "Table 2", Href: "OEBPS/text/ch2.xhtml#t2", SpineIndex: -1, SpineEndIndex: -1},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNCXNavLists() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBook_NavLists(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:navlists</dc:identifier>
+    <dc:title>Nav Lists</dc:title>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+  </spine>
+</package>`,
+		"OEBPS/nav.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<nav epub:type="toc"><ol><li><a href="ch1.xhtml">One</a></li><li><a href="ch2.xhtml">Two</a></li></ol></nav>
+<nav epub:type="loi" hidden=""><h2>Figures</h2><ol><li><a href="ch2.xhtml#f1">Figure 1</a></li></ol></nav>
+</body></html>`,
+		"OEBPS/ch1.xhtml": `<html><body><p>One</p></body></html>`,
+		"OEBPS/ch2.xhtml": `<html><body><p>Two</p></body></html>`,
+	})
+
+	lists := book.NavLists()
+	if len(lists) != 2 {
+		t.Fatalf("NavLists() count = %d, want 2", len(lists))
+	}
+	loi := lists[1]
+	if loi.Type != "loi" || loi.Title != "Figures" || !loi.Hidden {
+		t.Errorf("NavLists()[1] = %+v, want hidden loi titled Figures", loi)
+	}
+	want := []TOCItem{{Title: "Figure 1", Href: "OEBPS/ch2.xhtml#f1", SpineIndex: 1, SpineEndIndex: 2}}
+	if !reflect.DeepEqual(loi.Items, want) {
+		t.Errorf("loi Items = %+v, want %+v", loi.Items, want)
+	}
+
+	// The result is a deep copy.
+	lists[1].Items[0].Title = "changed"
+	if book.NavLists()[1].Items[0].Title != "Figure 1" {
+		t.Error("NavLists() returned shared items")
+	}
+}
+
+func TestBook_NavLists_NCXFallback(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:navlists</dc:identifier>
+    <dc:title>Nav Lists</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx"><itemref idref="ch1"/></spine>
+</package>`,
+		"OEBPS/toc.ncx": `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="n1" playOrder="1"><navLabel><text>One</text></navLabel><content src="ch1.xhtml"/></navPoint>
+  </navMap>
+  <navList class="loi">
+    <navLabel><text>Illustrations</text></navLabel>
+    <navTarget id="i1" playOrder="2"><navLabel><text>Map</text></navLabel><content src="ch1.xhtml#map"/></navTarget>
+  </navList>
+</ncx>`,
+		"OEBPS/ch1.xhtml": `<html><body><p>One</p></body></html>`,
+	})
+
+	want := []NavList{{Type: "loi", Title: "Illustrations", Items: []TOCItem{
+		{Title: "Map", Href: "OEBPS/ch1.xhtml#map", SpineIndex: 0, SpineEndIndex: 1},
+	}}}
+	if got := book.NavLists(); !reflect.DeepEqual(got, want) {
+		t.Errorf("NavLists() = %+v, want %+v", got, want)
+	}
+}
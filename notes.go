@@ -0,0 +1,562 @@
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// NoteMode selects how TextContentWithNotes handles notes.
+type NoteMode int
+
+// Note modes.
+const (
+	// NotesKeep leaves note references and bodies in place, as TextContent
+	// does.
+	NotesKeep NoteMode = iota
+	// NotesOmit removes note references and note bodies.
+	NotesOmit
+	// NotesInline replaces each note reference with the note text in
+	// square brackets and removes the note bodies.
+	NotesInline
+	// NotesEnd replaces each note reference with its label in square
+	// brackets, removes the note bodies, and appends the notes referenced
+	// by the chapter after its text, one per paragraph.
+	NotesEnd
+)
+
+// noteTypes is the set of epub:type (and DPUB-ARIA role) values that mark a
+// note body.
+var noteTypes = map[string]string{
+	"footnote":     "footnote",
+	"endnote":      "endnote",
+	"rearnote":     "rearnote",
+	"note":         "note",
+	"doc-footnote": "footnote",
+	"doc-endnote":  "endnote",
+}
+
+// noteMarkerPattern matches the text of a plain link that looks like a note
+// reference: a number, a symbol, a single letter, or a small roman numeral,
+// optionally in brackets.
+var noteMarkerPattern = regexp.MustCompile(`^[\[(]?(?:\d{1,4}|[*†‡§¶]{1,3}|[a-z]|[ivxlc]{1,6})[\])]?$`)
+
+// noteFragmentPattern matches fragment ids that name notes.
+var noteFragmentPattern = regexp.MustCompile(`(?i)^(?:.*[^a-z])?(?:(?:end|foot|rear)?notes?|fn|ftn)(?:[^a-z].*)?$`)
+
+// Notes returns every note reference of the spine documents resolved to its
+// note body, in reading order. References are <a> elements with
+// epub:type="noteref" (or role="doc-noteref"), and plain links whose text
+// looks like a marker ("1", "[2]", "*") and that are set in <sup>, bracketed,
+// or point to a note-like fragment (e.g., "#fn1"). Links back from a note to
+// its reference are not references. Note bodies may live in any document of
+// the archive.
+//
+// References whose target cannot be found are skipped with a warning. The
+// result is computed on the first call and cached.
+func (b *Book) Notes() []Note {
+	if !b.notesBuilt {
+		b.notes = b.buildNotes()
+		b.notesBuilt = true
+	}
+	out := make([]Note, len(b.notes))
+	copy(out, b.notes)
+	return out
+}
+
+// noteAnchor is a candidate note reference found in a document.
+type noteAnchor struct {
+	node     *html.Node
+	file     string // ZIP path of the document
+	id       string // id of the anchor or its <sup>/<span> wrapper
+	target   string // resolved ZIP path#fragment of the href
+	label    string
+	explicit bool // marked with epub:type noteref
+	order    int  // position in reading order
+}
+
+// noteDocuments caches parsed documents while resolving notes.
+type noteDocuments struct {
+	b    *Book
+	docs map[string]*html.Node
+}
+
+// get returns the parsed document at the ZIP path file, or nil.
+func (d *noteDocuments) get(file string) *html.Node {
+	if doc, ok := d.docs[file]; ok {
+		return doc
+	}
+	var doc *html.Node
+	if data, err := d.b.readFile(file); err == nil {
+		doc, _ = html.Parse(bytes.NewReader(normalizeSelfClosingSkipTags(stripBOM(data))))
+	}
+	d.docs[file] = doc
+	return doc
+}
+
+// buildNotes scans every spine document for note references and resolves
+// them.
+func (b *Book) buildNotes() []Note {
+	docs := &noteDocuments{b: b, docs: make(map[string]*html.Node)}
+	spineMap := b.spineIndexMap()
+
+	var anchors []noteAnchor
+	for _, si := range b.spine {
+		file := b.resolveOPFPath(si.Href)
+		if doc := docs.get(file); doc != nil {
+			anchors = append(anchors, scanNoteAnchors(doc, file, len(anchors))...)
+		}
+	}
+
+	// Resolve every target first, so that a note body never extends over
+	// another note's target.
+	refs := noteReferences(anchors)
+	resolved := make([]*html.Node, len(refs))
+	targets := make(map[*html.Node]bool)
+	for i, a := range refs {
+		file, frag, _ := strings.Cut(a.target, "#")
+		if doc := docs.get(file); doc != nil {
+			resolved[i] = findElementByID(doc, frag)
+		}
+		if resolved[i] == nil {
+			b.warnings = append(b.warnings, fmt.Sprintf("note reference %q in %s: target %s not found", a.label, a.file, a.target))
+			continue
+		}
+		targets[resolved[i]] = true
+	}
+
+	var notes []Note
+	for i, a := range refs {
+		target := resolved[i]
+		if target == nil {
+			continue
+		}
+		file, _, _ := strings.Cut(a.target, "#")
+		body := noteBody(target, targets)
+		refHref := a.file
+		if a.id != "" {
+			refHref += "#" + a.id
+		}
+		n := Note{
+			Label:         a.label,
+			Type:          noteType(body[0]),
+			RefHref:       refHref,
+			RefSpineIndex: -1,
+			Href:          a.target,
+			SpineIndex:    -1,
+			Text:          noteText(body, file, refHref),
+		}
+		if i, ok := spineMap[a.file]; ok {
+			n.RefSpineIndex = i
+		}
+		if i, ok := spineMap[file]; ok {
+			n.SpineIndex = i
+		}
+		notes = append(notes, n)
+	}
+	return notes
+}
+
+// scanNoteAnchors returns every same-archive link of a document that could
+// be a note reference, numbering them in order from start.
+func scanNoteAnchors(doc *html.Node, file string, start int) []noteAnchor {
+	var anchors []noteAnchor
+	var walk func(n *html.Node, inNote bool)
+	walk = func(n *html.Node, inNote bool) {
+		if n.Type == html.ElementNode {
+			if noteType(n) != "" {
+				inNote = true
+			}
+			if n.DataAtom == atom.A {
+				if a, ok := noteAnchorFor(n, file, inNote); ok {
+					a.order = start + len(anchors)
+					anchors = append(anchors, a)
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inNote)
+		}
+	}
+	walk(doc, false)
+	return anchors
+}
+
+// noteAnchorFor returns the candidate reference for the <a> element n.
+// Links inside a note body are only references when explicitly marked.
+func noteAnchorFor(n *html.Node, file string, inNote bool) (noteAnchor, bool) {
+	href := strings.TrimSpace(navGetAttr(n, "href"))
+	if href == "" || hasURIScheme(href) {
+		return noteAnchor{}, false
+	}
+	targetFile, frag, _ := strings.Cut(href, "#")
+	if frag == "" {
+		return noteAnchor{}, false
+	}
+	if targetFile == "" {
+		targetFile = file
+	} else if targetFile = resolveRelativePath(file, targetFile); targetFile == "" {
+		return noteAnchor{}, false
+	}
+
+	a := noteAnchor{
+		node:     n,
+		file:     file,
+		id:       navGetAttr(n, "id"),
+		target:   targetFile + "#" + frag,
+		label:    strings.Trim(inlineText(n), "[]() "),
+		explicit: hasEpubType(n, "noteref") || hasRole(n, "doc-noteref"),
+	}
+	wrapper := n.Parent
+	inSup := wrapper != nil && (wrapper.DataAtom == atom.Sup || wrapper.DataAtom == atom.Span) && wrapper.FirstChild == n && n.NextSibling == nil
+	if a.id == "" && inSup {
+		a.id = navGetAttr(wrapper, "id")
+	}
+	if a.explicit {
+		return a, true
+	}
+	if inNote || !noteMarkerPattern.MatchString(inlineText(n)) {
+		return noteAnchor{}, false
+	}
+	bracketed := strings.ContainsAny(inlineText(n), "[(")
+	if !inSup && !bracketed && !noteFragmentPattern.MatchString(frag) {
+		return noteAnchor{}, false
+	}
+	return a, true
+}
+
+// noteReferences filters the candidate anchors down to note references. A
+// plain link inside the element that an earlier reference points to, and
+// that links back to that reference, is a back-link.
+func noteReferences(anchors []noteAnchor) []noteAnchor {
+	byID := make(map[string]*noteAnchor)
+	for i := range anchors {
+		if anchors[i].id != "" {
+			byID[anchors[i].file+"#"+anchors[i].id] = &anchors[i]
+		}
+	}
+
+	var refs []noteAnchor
+	for _, a := range anchors {
+		if other, ok := byID[a.target]; ok && !a.explicit && other.order < a.order {
+			otherFile, otherFrag, _ := strings.Cut(other.target, "#")
+			if otherFile == a.file && hasAncestorID(a.node, otherFrag) {
+				continue
+			}
+		}
+		refs = append(refs, a)
+	}
+	return refs
+}
+
+// hasAncestorID reports whether n or one of its ancestors has the id (or,
+// for <a>, the name) id.
+func hasAncestorID(n *html.Node, id string) bool {
+	for ; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		if navGetAttr(n, "id") == id || (n.DataAtom == atom.A && navGetAttr(n, "name") == id) {
+			return true
+		}
+	}
+	return false
+}
+
+// noteBody returns the nodes holding the note that target (the element a
+// reference points to) belongs to: the nearest ancestor marked as a note,
+// else target itself when it is a block element. An inline target belongs
+// to its nearest block ancestor, unless that block also holds other note
+// targets (as in "<div><a id="fn1"/>1. …<br/><a id="fn2"/>2. …</div>"); the
+// note is then the run of the block's children from the one holding target
+// up to the one holding the next target.
+func noteBody(target *html.Node, targets map[*html.Node]bool) []*html.Node {
+	for n := target; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		if noteType(n) != "" {
+			return []*html.Node{n}
+		}
+	}
+	if isBlockElement(target) {
+		return []*html.Node{target}
+	}
+	block := target.Parent
+	for block != nil && block.Type == html.ElementNode && !isBlockElement(block) {
+		block = block.Parent
+	}
+	if block == nil || block.Type != html.ElementNode {
+		return []*html.Node{target}
+	}
+
+	start := target
+	for start.Parent != block {
+		start = start.Parent
+	}
+	holdsOther := func(n *html.Node) bool {
+		return n != start && holdsNoteTarget(n, target, targets)
+	}
+	shared := false
+	for c := block.FirstChild; c != nil && !shared; c = c.NextSibling {
+		shared = holdsOther(c)
+	}
+	if !shared {
+		return []*html.Node{block}
+	}
+	run := []*html.Node{start}
+	for c := start.NextSibling; c != nil && !holdsOther(c); c = c.NextSibling {
+		run = append(run, c)
+	}
+	return run
+}
+
+// holdsNoteTarget reports whether n or one of its descendants is in
+// targets, other than target itself.
+func holdsNoteTarget(n, target *html.Node, targets map[*html.Node]bool) bool {
+	if n != target && targets[n] {
+		return true
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if holdsNoteTarget(c, target, targets) {
+			return true
+		}
+	}
+	return false
+}
+
+// noteType returns the note type of an element marked with a note epub:type
+// or role, or "".
+func noteType(n *html.Node) string {
+	if n == nil || n.Type != html.ElementNode {
+		return ""
+	}
+	for _, key := range []string{"epub:type", "role"} {
+		for _, v := range strings.Fields(navGetAttr(n, key)) {
+			if t, ok := noteTypes[v]; ok {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// hasRole reports whether n's role attribute contains role.
+func hasRole(n *html.Node, role string) bool {
+	for _, v := range strings.Fields(navGetAttr(n, "role")) {
+		if v == role {
+			return true
+		}
+	}
+	return false
+}
+
+// noteText returns the text of the note body nodes, leaving out links back
+// to the reference at refHref (the body lives in the document at file) and
+// a leading marker link.
+func noteText(body []*html.Node, file, refHref string) string {
+	refFile, refID, _ := strings.Cut(refHref, "#")
+	first := true
+	skip := func(n *html.Node) bool {
+		if n.DataAtom != atom.A {
+			return false
+		}
+		href := strings.TrimSpace(navGetAttr(n, "href"))
+		hrefFile, frag, _ := strings.Cut(href, "#")
+		if hrefFile == "" {
+			hrefFile = file
+		} else {
+			hrefFile = resolveRelativePath(file, hrefFile)
+		}
+		isBackLink := hrefFile == refFile && (refID == "" || frag == refID)
+		leadingMarker := first && noteMarkerPattern.MatchString(inlineText(n))
+		first = false
+		return isBackLink || leadingMarker
+	}
+	return blockText(body, skip)
+}
+
+// blockText returns the text of the subtrees rooted at nodes like
+// extractText: whitespace collapsed, a line break around block elements and
+// at <br>. Elements for which skip returns true are left out.
+func blockText(nodes []*html.Node, skip func(*html.Node) bool) string {
+	var buf strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			buf.WriteString(collapseWhitespace(n.Data))
+			return
+		case n.Type != html.ElementNode:
+			return
+		case skipTags[n.DataAtom], skip != nil && skip(n):
+			return
+		}
+		if blockTags[n.DataAtom] {
+			buf.WriteByte('\n')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if blockTags[n.DataAtom] {
+			buf.WriteByte('\n')
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TextContentWithNotes is like TextContent, but handles note references and
+// note bodies as selected by mode (see Book.Notes). With any mode other than
+// NotesKeep, note bodies in this chapter are removed, so the text of an
+// endnotes chapter may become empty.
+func (c Chapter) TextContentWithNotes(mode NoteMode) (string, error) {
+	data, err := c.RawContent()
+	if err != nil {
+		return "", err
+	}
+	if mode == NotesKeep {
+		return extractText(data)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(normalizeSelfClosingSkipTags(data)))
+	if err != nil {
+		return "", err
+	}
+	notes := c.book.bookNotes()
+	end := applyNoteMode(doc, c.Href, notes, mode)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", err
+	}
+	text, err := extractText(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	if len(end) > 0 {
+		text = strings.TrimRight(text, "\n") + "\n\n" + strings.Join(end, "\n\n")
+	}
+	return text, nil
+}
+
+// bookNotes implements the bookReader interface.
+func (b *Book) bookNotes() []Note {
+	return b.Notes()
+}
+
+// applyNoteMode rewrites the parsed document at file for mode: note bodies
+// are removed and references are removed or replaced. It returns the
+// paragraphs to append for NotesEnd.
+func applyNoteMode(doc *html.Node, file string, notes []Note, mode NoteMode) []string {
+	var end []string
+	matchNoteReferences(doc, file, notes, func(a *html.Node, i int) {
+		n := notes[i]
+		var replacement string
+		switch mode {
+		case NotesInline:
+			replacement = " [" + strings.Join(strings.Fields(n.Text), " ") + "]"
+		case NotesEnd:
+			replacement = "[" + n.Label + "]"
+			end = append(end, "["+n.Label+"] "+n.Text)
+		}
+		var text *html.Node
+		if replacement != "" {
+			text = &html.Node{Type: html.TextNode, Data: replacement}
+		}
+		replaceNoteAnchor(a, text)
+	})
+	removeNotes(doc, file, notes)
+	return end
+}
+
+// matchNoteReferences calls fn, in document order, for every reference link
+// in the parsed document at file that belongs to one of notes, with the
+// index of that note.
+func matchNoteReferences(doc *html.Node, file string, notes []Note, fn func(a *html.Node, i int)) {
+	refs := make(map[string][]int)
+	for i, n := range notes {
+		if refFile, _, _ := strings.Cut(n.RefHref, "#"); refFile == file {
+			key := n.RefHref + "\x00" + n.Href
+			refs[key] = append(refs[key], i)
+		}
+	}
+
+	for _, a := range scanNoteAnchors(doc, file, 0) {
+		refHref := a.file
+		if a.id != "" {
+			refHref += "#" + a.id
+		}
+		key := refHref + "\x00" + a.target
+		if len(refs[key]) == 0 {
+			continue
+		}
+		i := refs[key][0]
+		refs[key] = refs[key][1:]
+		fn(a.node, i)
+	}
+}
+
+// removeNotes removes from the parsed document at file the bodies of notes
+// located there, and every other element marked as a note.
+func removeNotes(doc *html.Node, file string, notes []Note) {
+	targets := make(map[*html.Node]bool)
+	for _, n := range notes {
+		noteFile, frag, _ := strings.Cut(n.Href, "#")
+		if noteFile != file {
+			continue
+		}
+		if target := findElementByID(doc, frag); target != nil {
+			targets[target] = true
+		}
+	}
+	// Find every body before removing any, so that removals do not change
+	// which nodes a body spans.
+	var bodies []*html.Node
+	for target := range targets {
+		bodies = append(bodies, noteBody(target, targets)...)
+	}
+	for _, body := range bodies {
+		if body.Parent != nil {
+			body.Parent.RemoveChild(body)
+		}
+	}
+	removeNoteBodies(doc)
+}
+
+// replaceNoteAnchor replaces a reference link (and a <sup> wrapper holding
+// only the link) with the node r, or removes it when r is nil.
+func replaceNoteAnchor(a, r *html.Node) {
+	n := a
+	if p := a.Parent; p != nil && p.DataAtom == atom.Sup && p.FirstChild == a && a.NextSibling == nil {
+		n = p
+	}
+	if n.Parent == nil {
+		return
+	}
+	if r != nil {
+		n.Parent.InsertBefore(r, n)
+	}
+	n.Parent.RemoveChild(n)
+}
+
+// removeNoteBodies removes every element marked as a note.
+func removeNoteBodies(n *html.Node) {
+	var next *html.Node
+	for c := n.FirstChild; c != nil; c = next {
+		next = c.NextSibling
+		if noteType(c) != "" {
+			n.RemoveChild(c)
+			continue
+		}
+		removeNoteBodies(c)
+	}
+}
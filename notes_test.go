@@ -0,0 +1,151 @@
+package epub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func openNotesTestBook(t *testing.T) *Book {
+	t.Helper()
+	return openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:notes</dc:identifier>
+    <dc:title>Notes</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="notes" href="notes.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+    <itemref idref="notes"/>
+  </spine>
+</package>`,
+		"OEBPS/ch1.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<p>Text<a epub:type="noteref" id="r1" href="#fn1">1</a> and more<a epub:type="noteref" id="r2" href="notes.xhtml#en2">2</a>.</p>
+<p>See <a href="ch2.xhtml#part">part two</a>.</p>
+<aside epub:type="footnote" id="fn1"><p><a href="#r1">1</a> A footnote.</p></aside>
+</body></html>`,
+		"OEBPS/ch2.xhtml": `<html><body>
+<p id="part">Plain<sup><a id="r3" href="notes.xhtml#note3">3</a></sup> reference, and a missing one<a href="#fn9">[9]</a>.</p>
+</body></html>`,
+		"OEBPS/notes.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<h1>Notes</h1>
+<ol>
+<li epub:type="endnote" id="en2"><p>An endnote. <a href="ch1.xhtml#r2">Back</a></p></li>
+</ol>
+<p id="note3"><a href="ch2.xhtml#r3">3</a> A plain note.</p>
+</body></html>`,
+	})
+}
+
+func TestBook_Notes(t *testing.T) {
+	book := openNotesTestBook(t)
+
+	want := []Note{
+		{Label: "1", Type: "footnote", RefHref: "OEBPS/ch1.xhtml#r1", RefSpineIndex: 0, Href: "OEBPS/ch1.xhtml#fn1", SpineIndex: 0, Text: "A footnote."},
+		{Label: "2", Type: "endnote", RefHref: "OEBPS/ch1.xhtml#r2", RefSpineIndex: 0, Href: "OEBPS/notes.xhtml#en2", SpineIndex: 2, Text: "An endnote."},
+		{Label: "3", RefHref: "OEBPS/ch2.xhtml#r3", RefSpineIndex: 1, Href: "OEBPS/notes.xhtml#note3", SpineIndex: 2, Text: "A plain note."},
+	}
+	if got := book.Notes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Notes() =\n%+v\nwant\n%+v", got, want)
+	}
+	if !containsWarning(book.Warnings(), "target OEBPS/ch2.xhtml#fn9 not found") {
+		t.Errorf("Warnings() = %v, want missing note target warning", book.Warnings())
+	}
+}
+
+func TestChapter_TextContentWithNotes(t *testing.T) {
+	book := openNotesTestBook(t)
+	ch := book.Chapters()[0]
+
+	tests := []struct {
+		mode NoteMode
+		want string
+	}{
+		{NotesKeep, "Text1 and more2.\nSee part two.\n1 A footnote."},
+		{NotesOmit, "Text and more.\nSee part two."},
+		{NotesInline, "Text [A footnote.] and more [An endnote.].\nSee part two."},
+		{NotesEnd, "Text[1] and more[2].\nSee part two.\n\n[1] A footnote.\n\n[2] An endnote."},
+	}
+	for _, tt := range tests {
+		got, err := ch.TextContentWithNotes(tt.mode)
+		if err != nil {
+			t.Fatalf("TextContentWithNotes(%d) error = %v", tt.mode, err)
+		}
+		if got != tt.want {
+			t.Errorf("TextContentWithNotes(%d) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+
+	notes, err := book.Chapters()[2].TextContentWithNotes(NotesOmit)
+	if err != nil {
+		t.Fatalf("TextContentWithNotes() error = %v", err)
+	}
+	if notes != "Notes" {
+		t.Errorf("TextContentWithNotes(NotesOmit) of notes chapter = %q, want %q", notes, "Notes")
+	}
+}
+
+func TestNoteMarkerPattern(t *testing.T) {
+	tests := map[string]bool{
+		"1": true, "[12]": true, "(a)": true, "*": true, "†": true, "iv": true,
+		"part two": false, "Chapter 1": false, "12345": false,
+	}
+	for s, want := range tests {
+		if got := noteMarkerPattern.MatchString(s); got != want {
+			t.Errorf("noteMarkerPattern.MatchString(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestBook_Notes_SharedContainer(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:shared-notes</dc:identifier>
+    <dc:title>Shared</dc:title>
+  </metadata>
+  <manifest><item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/></manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`,
+		"OEBPS/ch1.xhtml": `<html><body>
+<p>One<a href="#fn1">1</a> two<a href="#fn2">2</a>.</p>
+<div><h2>Notes</h2><a id="fn1"></a>1. First note.<br/><a id="fn2"></a>2. Second <i>note</i>.</div>
+</body></html>`,
+	})
+
+	var texts []string
+	for _, n := range book.Notes() {
+		texts = append(texts, n.Text)
+	}
+	if want := []string{"1. First note.", "2. Second note."}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("Notes() texts = %q, want %q", texts, want)
+	}
+
+	got, err := book.Chapters()[0].TextContentWithNotes(NotesOmit)
+	if err != nil {
+		t.Fatalf("TextContentWithNotes() error = %v", err)
+	}
+	if want := "One two.\nNotes"; got != want {
+		t.Errorf("TextContentWithNotes(NotesOmit) = %q, want %q", got, want)
+	}
+}
+
+func TestNoteFragmentPattern(t *testing.T) {
+	tests := map[string]bool{
+		"fn1": true, "FN-2": true, "ftn5": true, "note3": true, "notes": true,
+		"footnote_4": true, "endnote12": true, "ch01fn3": true, "chapter1-note-2": true,
+		"annotated": false, "notebook": false, "nfn": false, "part": false,
+	}
+	for s, want := range tests {
+		if got := noteFragmentPattern.MatchString(s); got != want {
+			t.Errorf("noteFragmentPattern.MatchString(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
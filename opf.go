@@ -10,10 +10,15 @@ type opfPackage struct {
 	XMLName          xml.Name    `xml:"package"`
 	Version          string      `xml:"version,attr"`
 	UniqueIdentifier string      `xml:"unique-identifier,attr"`
+	Lang             string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Dir              string      `xml:"dir,attr"`
 	Metadata         opfMetadata `xml:"metadata"`
 	Manifest         opfManifest `xml:"manifest"`
 	Spine            opfSpine    `xml:"spine"`
 	Guide            opfGuide    `xml:"guide"`
+
+	// Collections holds ePub 3.0.1 <collection> elements (direct children of <package>).
+	Collections []opfCollection `xml:"collection"`
 }
 
 // opfMetadata holds the raw metadata elements from the OPF file.
@@ -29,6 +34,7 @@ type opfMetadata struct {
 	Rights       []opfDCElement `xml:"http://purl.org/dc/elements/1.1/ rights"`
 	Sources      []opfDCElement `xml:"http://purl.org/dc/elements/1.1/ source"`
 	Metas        []opfMeta      `xml:"meta"`
+	Links        []opfLink      `xml:"link"`
 }
 
 // opfDCElement holds a Dublin Core element with optional OPF attributes.
@@ -40,6 +46,9 @@ type opfDCElement struct {
 	FileAs string `xml:"file-as,attr"`
 	Role   string `xml:"role,attr"`
 	Scheme string `xml:"scheme,attr"`
+	Event  string `xml:"event,attr"`
+	Lang   string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Dir    string `xml:"dir,attr"`
 }
 
 // opfMeta represents a <meta> element in the OPF metadata.
@@ -51,14 +60,36 @@ type opfMeta struct {
 	Content string `xml:"content,attr"`
 
 	// ePub 3 attributes.
+	ID       string `xml:"id,attr"`
 	Property string `xml:"property,attr"`
 	Refines  string `xml:"refines,attr"`
 	Scheme   string `xml:"scheme,attr"`
+	Lang     string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Dir      string `xml:"dir,attr"`
 
 	// ePub 3 text content.
 	Value string `xml:",chardata"`
 }
 
+// opfLink represents a <link> element in the OPF metadata (ePub 3), e.g.
+// <link rel="dcterms:conformsTo" href="..."/>.
+type opfLink struct {
+	Rel        string `xml:"rel,attr"`
+	Href       string `xml:"href,attr"`
+	Refines    string `xml:"refines,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr"`
+}
+
+// opfCollection represents an ePub 3.0.1 <collection> element. Collections
+// carry their own metadata block and may be nested.
+type opfCollection struct {
+	ID          string          `xml:"id,attr"`
+	Role        string          `xml:"role,attr"`
+	Metadata    opfMetadata     `xml:"metadata"`
+	Collections []opfCollection `xml:"collection"`
+}
+
 // opfManifest wraps the <manifest> element.
 type opfManifest struct {
 	Items []opfManifestItem `xml:"item"`
@@ -74,14 +105,16 @@ type opfManifestItem struct {
 
 // opfSpine wraps the <spine> element.
 type opfSpine struct {
-	Toc      string            `xml:"toc,attr"`
-	ItemRefs []opfSpineItemRef `xml:"itemref"`
+	Toc                      string            `xml:"toc,attr"`
+	PageProgressionDirection string            `xml:"page-progression-direction,attr"`
+	ItemRefs                 []opfSpineItemRef `xml:"itemref"`
 }
 
 // opfSpineItemRef represents a single <itemref> in the spine.
 type opfSpineItemRef struct {
-	IDRef  string `xml:"idref,attr"`
-	Linear string `xml:"linear,attr"`
+	IDRef      string `xml:"idref,attr"`
+	Linear     string `xml:"linear,attr"`
+	Properties string `xml:"properties,attr"`
 }
 
 // opfGuide wraps the <guide> element.
@@ -105,11 +138,8 @@ type guideReference struct {
 
 // parseOPF parses the OPF file content and returns the parsed package structure.
 func parseOPF(data []byte) (*opfPackage, error) {
-	data = preprocessHTMLEntities(data)
-	data = stripBOM(data)
-
 	var pkg opfPackage
-	if err := xml.Unmarshal(data, &pkg); err != nil {
+	if err := decodeXML(data, &pkg); err != nil {
 		return nil, fmt.Errorf("epub: parse OPF: %w", err)
 	}
 
@@ -148,8 +178,9 @@ func buildSpine(spine opfSpine, manifestByID map[string]*manifestItem) []spineIt
 
 	for _, ref := range spine.ItemRefs {
 		si := spineItem{
-			IDRef:  ref.IDRef,
-			Linear: ref.Linear != "no",
+			IDRef:      ref.IDRef,
+			Linear:     ref.Linear != "no",
+			Properties: ref.Properties,
 		}
 		if mi, ok := manifestByID[ref.IDRef]; ok {
 			si.ID = mi.ID
@@ -0,0 +1,207 @@
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PageList returns the print page list of the publication: each print page
+// label mapped to its archive href and spine index.
+//
+// Sources are tried in order: the ePub 3 nav document's
+// epub:type="page-list" nav, the NCX <pageList>, and finally a scan of the
+// spine content for epub:type="pagebreak" (or role="doc-pagebreak") elements.
+// The result is computed on the first call and cached; it is nil when the
+// publication has no page information at all.
+func (b *Book) PageList() []PageTarget {
+	b.buildPageList()
+	return append([]PageTarget(nil), b.pageList...)
+}
+
+// buildPageList resolves the page list from the first source that has one,
+// recording whether it was declared by the nav document or NCX. It runs at
+// most once per Book.
+func (b *Book) buildPageList() {
+	if b.pageListBuilt {
+		return
+	}
+	b.pageList, b.pagesDeclared = b.declaredPageList()
+	if !b.pagesDeclared {
+		b.pageList = b.scanPageBreaks()
+	}
+	b.pageListBuilt = true
+}
+
+// declaredPageList returns the page list declared by the nav document or, failing
+// that, the NCX. Reports false when neither declares a non-empty page list.
+func (b *Book) declaredPageList() ([]PageTarget, bool) {
+	spineMap := b.spineIndexMap()
+
+	if navPath := b.navDocumentPath(); navPath != "" {
+		if data, err := b.readContent(navPath); err == nil {
+			if targets, err := parseNavPageList(data, navPath); err == nil && len(targets) > 0 {
+				assignPageSpineIndices(targets, spineMap)
+				return targets, true
+			}
+		}
+	}
+
+	if ncxPath := b.ncxPath(); ncxPath != "" {
+		if data, err := b.readContent(ncxPath); err == nil {
+			targets, err := parseNCXPageList(data, ncxPath)
+			if err != nil {
+				b.warnings = append(b.warnings, fmt.Sprintf("failed to parse NCX page list: %v", err))
+			} else if len(targets) > 0 {
+				assignPageSpineIndices(targets, spineMap)
+				return targets, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// parseNavPageList extracts the entries of the epub:type="page-list" nav in
+// an ePub 3 nav document. basePath is the ZIP-internal path of the nav document.
+func parseNavPageList(data []byte, basePath string) ([]PageTarget, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("epub: parse nav document: %w", err)
+	}
+
+	nav := findNavByType(doc, "page-list")
+	if nav == nil {
+		return nil, nil
+	}
+	ol := findFirstChildElement(nav, "ol")
+	if ol == nil {
+		return nil, nil
+	}
+
+	var flat []*TOCItem
+	items := parseNavOL(ol, basePath)
+	flattenTOCItems(&flat, items)
+
+	targets := make([]PageTarget, 0, len(flat))
+	for _, item := range flat {
+		if item.Title == "" && item.Href == "" {
+			continue
+		}
+		targets = append(targets, PageTarget{Label: item.Title, Href: item.Href, SpineIndex: -1})
+	}
+	return targets, nil
+}
+
+// findNavByType returns the first <nav> element whose epub:type contains typeName.
+func findNavByType(n *html.Node, typeName string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == "nav" && hasEpubType(n, typeName) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findNavByType(c, typeName); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// parseNCXPageList extracts the <pageList> entries of an NCX document.
+// ncxPath is the ZIP-internal path of the NCX file.
+func parseNCXPageList(data []byte, ncxPath string) ([]PageTarget, error) {
+	doc, err := decodeNCX(data)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]PageTarget, 0, len(doc.PageList.Targets))
+	for _, pt := range doc.PageList.Targets {
+		label := strings.TrimSpace(pt.Label.Text)
+		if label == "" {
+			label = strings.TrimSpace(pt.Value)
+		}
+		t := PageTarget{
+			Label:      label,
+			SpineIndex: -1,
+			Type:       strings.TrimSpace(pt.Type),
+		}
+		if src := strings.TrimSpace(pt.Content.Src); src != "" {
+			t.Href = resolveRelativePath(ncxPath, src)
+		}
+		if t.Label == "" && t.Href == "" {
+			continue
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// assignPageSpineIndices sets SpineIndex on each target by matching its
+// Href (without fragment) against the spine map.
+func assignPageSpineIndices(targets []PageTarget, spineMap map[string]int) {
+	for i := range targets {
+		if idx, ok := spineMap[hrefWithoutFragment(targets[i].Href)]; ok {
+			targets[i].SpineIndex = idx
+		}
+	}
+}
+
+// scanPageBreaks builds a page list by scanning every spine document for
+// page-break markers: elements with epub:type="pagebreak" or
+// role="doc-pagebreak". The label is taken from the title or aria-label
+// attribute, falling back to the element's text.
+func (b *Book) scanPageBreaks() []PageTarget {
+	var targets []PageTarget
+	for i, si := range b.spine {
+		href := b.resolveOPFPath(si.Href)
+		if href == "" {
+			continue
+		}
+		data, err := b.readContent(href)
+		if err != nil {
+			continue
+		}
+		doc, err := html.Parse(bytes.NewReader(stripBOM(data)))
+		if err != nil {
+			continue
+		}
+
+		var walk func(*html.Node)
+		walk = func(n *html.Node) {
+			if n.Type == html.ElementNode && isPageBreak(n) {
+				label := strings.TrimSpace(navGetAttr(n, "title"))
+				if label == "" {
+					label = strings.TrimSpace(navGetAttr(n, "aria-label"))
+				}
+				if label == "" {
+					label = strings.TrimSpace(collapseWhitespace(nodeTextContent(n)))
+				}
+				target := href
+				if id := navGetAttr(n, "id"); id != "" {
+					target += "#" + id
+				}
+				targets = append(targets, PageTarget{Label: label, Href: target, SpineIndex: i})
+			}
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+		}
+		walk(doc)
+	}
+	return targets
+}
+
+// isPageBreak reports whether n is marked as a print page break.
+func isPageBreak(n *html.Node) bool {
+	if hasEpubType(n, "pagebreak") {
+		return true
+	}
+	for _, role := range strings.Fields(navGetAttr(n, "role")) {
+		if role == "doc-pagebreak" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package epub
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func openPageListTestBook(t *testing.T, files map[string]string) *Book {
+	t.Helper()
+	files["mimetype"] = "application/epub+zip"
+	files["META-INF/container.xml"] = validContainerXML
+	data := buildTestEPubBytes(t, files)
+	book, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	t.Cleanup(func() { book.Close() })
+	return book
+}
+
+func TestParseNavPageList(t *testing.T) {
+	nav := `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<body>
+  <nav epub:type="toc"><ol><li><a href="ch1.xhtml">Chapter 1</a></li></ol></nav>
+  <nav epub:type="page-list" hidden="">
+    <ol>
+      <li><a href="ch1.xhtml#p1">1</a></li>
+      <li><a href="text/ch2.xhtml#p2">2</a></li>
+      <li><a href="ch2.xhtml#pxii"> xii </a></li>
+    </ol>
+  </nav>
+</body>
+</html>`
+	got, err := parseNavPageList([]byte(nav), "OEBPS/nav.xhtml")
+	if err != nil {
+		t.Fatalf("parseNavPageList() error = %v", err)
+	}
+	want := []PageTarget{
+		{Label: "1", Href: "OEBPS/ch1.xhtml#p1", SpineIndex: -1},
+		{Label: "2", Href: "OEBPS/text/ch2.xhtml#p2", SpineIndex: -1},
+		{Label: "xii", Href: "OEBPS/ch2.xhtml#pxii", SpineIndex: -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNavPageList() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseNavPageList_Absent(t *testing.T) {
+	nav := `<html><body><nav epub:type="toc"><ol><li><a href="a.xhtml">A</a></li></ol></nav></body></html>`
+	got, err := parseNavPageList([]byte(nav), "nav.xhtml")
+	if err != nil {
+		t.Fatalf("parseNavPageList() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("parseNavPageList() = %+v, want empty", got)
+	}
+}
+
+func TestParseNCXPageList(t *testing.T) {
+	ncx := `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="n1" playOrder="1"><navLabel><text>Chapter 1</text></navLabel><content src="ch1.xhtml"/></navPoint>
+  </navMap>
+  <pageList>
+    <navLabel><text>Pages</text></navLabel>
+    <pageTarget id="pt1" type="front" value="1" playOrder="2">
+      <navLabel><text>i</text></navLabel>
+      <content src="ch1.xhtml#pi"/>
+    </pageTarget>
+    <pageTarget id="pt2" type="normal" value="1" playOrder="3">
+      <navLabel><text></text></navLabel>
+      <content src="ch2.xhtml#p1"/>
+    </pageTarget>
+  </pageList>
+</ncx>`
+	got, err := parseNCXPageList([]byte(ncx), "OEBPS/toc.ncx")
+	if err != nil {
+		t.Fatalf("parseNCXPageList() error = %v", err)
+	}
+	want := []PageTarget{
+		{Label: "i", Href: "OEBPS/ch1.xhtml#pi", SpineIndex: -1, Type: "front"},
+		{Label: "1", Href: "OEBPS/ch2.xhtml#p1", SpineIndex: -1, Type: "normal"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNCXPageList() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBook_PageList_Nav(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:pages</dc:identifier>
+    <dc:title>Pages</dc:title>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+  </spine>
+</package>`,
+		"OEBPS/nav.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<nav epub:type="toc"><ol><li><a href="ch1.xhtml">One</a></li></ol></nav>
+<nav epub:type="page-list"><ol>
+  <li><a href="ch1.xhtml#p1">1</a></li>
+  <li><a href="ch2.xhtml#p2">2</a></li>
+  <li><a href="missing.xhtml#p3">3</a></li>
+</ol></nav>
+</body></html>`,
+		"OEBPS/ch1.xhtml": `<html><body><span epub:type="pagebreak" id="ignored" title="99"/></body></html>`,
+		"OEBPS/ch2.xhtml": `<html><body><p>Two</p></body></html>`,
+	})
+
+	want := []PageTarget{
+		{Label: "1", Href: "OEBPS/ch1.xhtml#p1", SpineIndex: 0},
+		{Label: "2", Href: "OEBPS/ch2.xhtml#p2", SpineIndex: 1},
+		{Label: "3", Href: "OEBPS/missing.xhtml#p3", SpineIndex: -1},
+	}
+	got := book.PageList()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PageList() = %+v, want %+v", got, want)
+	}
+
+	// The result is a copy.
+	got[0].Label = "changed"
+	if book.PageList()[0].Label != "1" {
+		t.Error("PageList() returned a shared slice")
+	}
+}
+
+func TestBook_PageList_NCX(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:pages</dc:identifier>
+    <dc:title>Pages</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="ch1"/>
+  </spine>
+</package>`,
+		"OEBPS/toc.ncx": `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="n1" playOrder="1"><navLabel><text>One</text></navLabel><content src="ch1.xhtml"/></navPoint>
+  </navMap>
+  <pageList>
+    <pageTarget id="pt1" type="normal" value="7" playOrder="2">
+      <navLabel><text>7</text></navLabel>
+      <content src="ch1.xhtml#page7"/>
+    </pageTarget>
+  </pageList>
+</ncx>`,
+		"OEBPS/ch1.xhtml": `<html><body><p>One</p></body></html>`,
+	})
+
+	want := []PageTarget{{Label: "7", Href: "OEBPS/ch1.xhtml#page7", SpineIndex: 0, Type: "normal"}}
+	if got := book.PageList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PageList() = %+v, want %+v", got, want)
+	}
+	if !book.hasPageList() {
+		t.Error("hasPageList() = false, want true")
+	}
+}
+
+func TestBook_PageList_PageBreakFallback(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:pages</dc:identifier>
+    <dc:title>Pages</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+  </spine>
+</package>`,
+		"OEBPS/ch1.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<span epub:type="pagebreak" id="p1" title="1"/>
+<p>Text</p>
+<div role="doc-pagebreak" id="p2" aria-label="2"></div>
+</body></html>`,
+		"OEBPS/ch2.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<span epub:type="pagebreak" id="p3"> 3 </span>
+</body></html>`,
+	})
+
+	want := []PageTarget{
+		{Label: "1", Href: "OEBPS/ch1.xhtml#p1", SpineIndex: 0},
+		{Label: "2", Href: "OEBPS/ch1.xhtml#p2", SpineIndex: 0},
+		{Label: "3", Href: "OEBPS/ch2.xhtml#p3", SpineIndex: 1},
+	}
+	if got := book.PageList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PageList() = %+v, want %+v", got, want)
+	}
+	if book.hasPageList() {
+		t.Error("hasPageList() = true, want false for page-break markers only")
+	}
+}
+
+func TestBook_PageList_None(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:pages</dc:identifier>
+    <dc:title>Pages</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`,
+		"OEBPS/ch1.xhtml": `<html><body><p>No pages</p></body></html>`,
+	})
+
+	if got := book.PageList(); got != nil {
+		t.Errorf("PageList() = %+v, want nil", got)
+	}
+}
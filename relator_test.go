@@ -0,0 +1,62 @@
+package epub
+
+import "testing"
+
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Role
+	}{
+		{"", ""},
+		{"aut", RoleAuthor},
+		{" TRL ", RoleTranslator},
+		{"Translator", RoleTranslator},
+		{"author of introduction, etc.", RoleAuthorOfIntroduction},
+		{"Ghostwriter", "Ghostwriter"},
+	}
+	for _, tt := range tests {
+		if got := ParseRole(tt.in); got != tt.want {
+			t.Errorf("ParseRole(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRole_Name(t *testing.T) {
+	tests := []struct {
+		role  Role
+		name  string
+		known bool
+	}{
+		{RoleAuthor, "Author", true},
+		{RoleEditor, "Editor", true},
+		{RoleIllustrator, "Illustrator", true},
+		{"bkp", "Book producer", true},
+		{"xyz", "xyz", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Name(); got != tt.name {
+			t.Errorf("Role(%q).Name() = %q, want %q", tt.role, got, tt.name)
+		}
+		if got := tt.role.IsKnown(); got != tt.known {
+			t.Errorf("Role(%q).IsKnown() = %v, want %v", tt.role, got, tt.known)
+		}
+	}
+}
+
+func TestMarcRelators_CodesAreNormalised(t *testing.T) {
+	if len(marcRelators) < 250 {
+		t.Errorf("marcRelators has %d entries, want the full relator list", len(marcRelators))
+	}
+	for code, name := range marcRelators {
+		if len(code) != 3 || ParseRole(string(code)) != code {
+			t.Errorf("relator code %q is not a normalised three-letter code", code)
+		}
+		if name == "" {
+			t.Errorf("relator code %q has no name", code)
+		}
+		if got := ParseRole(name); got != code {
+			t.Errorf("ParseRole(%q) = %q, want %q", name, got, code)
+		}
+	}
+}
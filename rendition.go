@@ -0,0 +1,159 @@
+package epub
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// extractRendition reads the global rendition:* metadata (metas without a
+// refines attribute) and the spine page-progression-direction.
+func extractRendition(opf *opfPackage) Rendition {
+	var r Rendition
+	for _, m := range opf.Metadata.Metas {
+		if m.Refines != "" {
+			continue
+		}
+		key, val := metaKeyValue(m)
+		if val == "" {
+			continue
+		}
+		switch key {
+		case "rendition:layout":
+			r.Layout = val
+		case "rendition:orientation":
+			r.Orientation = val
+		case "rendition:spread":
+			r.Spread = val
+		case "rendition:flow":
+			r.Flow = val
+		case "rendition:viewport":
+			r.Viewport = val
+		}
+	}
+	r.PageProgressionDirection = strings.TrimSpace(opf.Spine.PageProgressionDirection)
+	return r
+}
+
+// IsFixedLayout reports whether the rendition layout is "pre-paginated".
+func (r Rendition) IsFixedLayout() bool {
+	return r.Layout == "pre-paginated"
+}
+
+// applyItemRefProperties applies the per-itemref rendition overrides in props
+// to r, and returns the resulting rendition together with the requested page
+// spread ("left", "right", "center", or "").
+func applyItemRefProperties(r Rendition, props []string) (Rendition, string) {
+	spread := ""
+	for _, p := range props {
+		switch p {
+		case "page-spread-left", "rendition:page-spread-left":
+			spread = "left"
+		case "page-spread-right", "rendition:page-spread-right":
+			spread = "right"
+		case "page-spread-center", "rendition:page-spread-center":
+			spread = "center"
+		default:
+			if v, ok := strings.CutPrefix(p, "rendition:layout-"); ok {
+				r.Layout = v
+			} else if v, ok := strings.CutPrefix(p, "rendition:orientation-"); ok {
+				r.Orientation = v
+			} else if v, ok := strings.CutPrefix(p, "rendition:spread-"); ok {
+				r.Spread = v
+			} else if v, ok := strings.CutPrefix(p, "rendition:flow-"); ok {
+				r.Flow = v
+			}
+		}
+	}
+	return r, spread
+}
+
+// Rendition returns the global rendering properties declared in the package
+// document: rendition:layout, orientation, spread, flow, viewport, and the
+// spine page-progression-direction. Per-chapter overrides are available on
+// Chapter.Rendition.
+func (b *Book) Rendition() Rendition {
+	return b.rendition
+}
+
+// Viewport returns the dimensions declared by the chapter's
+// <meta name="viewport" content="width=..., height=..."> element, which
+// fixed-layout content documents must provide. If the document declares
+// none, the global rendition:viewport value is used; if neither is present
+// a zero Viewport is returned.
+func (c Chapter) Viewport() (Viewport, error) {
+	data, err := c.RawContent()
+	if err != nil {
+		return Viewport{}, err
+	}
+	if vp, ok := parseViewport(findViewportMeta(data)); ok {
+		return vp, nil
+	}
+	vp, _ := parseViewport(c.Rendition.Viewport)
+	return vp, nil
+}
+
+// findViewportMeta returns the content attribute of the first
+// <meta name="viewport"> element in the document head, or "".
+func findViewportMeta(htmlData []byte) string {
+	tokenizer := html.NewTokenizer(bytes.NewReader(htmlData))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tn, hasAttr := tokenizer.TagName()
+			a := atom.Lookup(tn)
+			if a == atom.Body {
+				return ""
+			}
+			if a != atom.Meta || !hasAttr {
+				continue
+			}
+			var name, content string
+			for {
+				key, val, more := tokenizer.TagAttr()
+				switch string(key) {
+				case "name":
+					name = string(val)
+				case "content":
+					content = string(val)
+				}
+				if !more {
+					break
+				}
+			}
+			if strings.EqualFold(strings.TrimSpace(name), "viewport") {
+				return content
+			}
+		}
+	}
+}
+
+// parseViewport parses a viewport declaration such as
+// "width=1200, height=1600". Non-numeric values (e.g., "device-width") are
+// ignored. Reports false unless both dimensions were found.
+func parseViewport(s string) (Viewport, bool) {
+	var vp Viewport
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(val)), "px")
+		n, err := strconv.Atoi(val)
+		if err != nil || n <= 0 {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "width":
+			vp.Width = n
+		case "height":
+			vp.Height = n
+		}
+	}
+	return vp, vp.Width > 0 && vp.Height > 0
+}
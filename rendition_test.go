@@ -0,0 +1,143 @@
+package epub
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const renditionTestOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid"
+         prefix="rendition: http://www.idpf.org/vocab/rendition/#">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:fxl</dc:identifier>
+    <dc:title>Comic</dc:title>
+    <meta property="rendition:layout">pre-paginated</meta>
+    <meta property="rendition:orientation">landscape</meta>
+    <meta property="rendition:spread">both</meta>
+    <meta property="rendition:flow">paginated</meta>
+    <meta property="rendition:viewport">width=1000, height=1500</meta>
+  </metadata>
+  <manifest>
+    <item id="p1" href="p1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="p2" href="p2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="p3" href="p3.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine page-progression-direction="rtl">
+    <itemref idref="p1" properties="page-spread-right"/>
+    <itemref idref="p2" properties="rendition:page-spread-center rendition:layout-reflowable rendition:spread-none"/>
+    <itemref idref="p3"/>
+  </spine>
+</package>`
+
+func openRenditionTestBook(t *testing.T) *Book {
+	t.Helper()
+	files := map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": validContainerXML,
+		"OEBPS/content.opf":      renditionTestOPF,
+		"OEBPS/p1.xhtml": `<html><head><meta name="viewport" content="width=1200, height=1800"/></head>
+<body><img src="p1.jpg" alt=""/></body></html>`,
+		"OEBPS/p2.xhtml": `<html><head><title>No viewport</title></head><body><p>Text</p></body></html>`,
+		"OEBPS/p3.xhtml": `<html><head><meta name="Viewport" content="width=device-width; height=900px; width=640"/></head><body/></html>`,
+	}
+	data := buildTestEPubBytes(t, files)
+	book, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	t.Cleanup(func() { book.Close() })
+	return book
+}
+
+func TestBook_Rendition(t *testing.T) {
+	book := openRenditionTestBook(t)
+
+	want := Rendition{
+		Layout:                   "pre-paginated",
+		Orientation:              "landscape",
+		Spread:                   "both",
+		Flow:                     "paginated",
+		Viewport:                 "width=1000, height=1500",
+		PageProgressionDirection: "rtl",
+	}
+	got := book.Rendition()
+	if got != want {
+		t.Errorf("Rendition() = %+v, want %+v", got, want)
+	}
+	if !got.IsFixedLayout() {
+		t.Error("IsFixedLayout() = false, want true")
+	}
+}
+
+func TestChapters_RenditionOverrides(t *testing.T) {
+	book := openRenditionTestBook(t)
+	chapters := book.Chapters()
+	if len(chapters) != 3 {
+		t.Fatalf("Chapters() count = %d, want 3", len(chapters))
+	}
+
+	if chapters[0].PageSpread != "right" || !chapters[0].Rendition.IsFixedLayout() {
+		t.Errorf("chapter 0 = spread %q, rendition %+v; want right, pre-paginated", chapters[0].PageSpread, chapters[0].Rendition)
+	}
+	if !reflect.DeepEqual(chapters[0].Properties, []string{"page-spread-right"}) {
+		t.Errorf("chapter 0 Properties = %v", chapters[0].Properties)
+	}
+
+	ch := chapters[1]
+	if ch.PageSpread != "center" {
+		t.Errorf("chapter 1 PageSpread = %q, want center", ch.PageSpread)
+	}
+	if ch.Rendition.Layout != "reflowable" || ch.Rendition.Spread != "none" || ch.Rendition.Orientation != "landscape" {
+		t.Errorf("chapter 1 Rendition = %+v, want reflowable/none with inherited landscape", ch.Rendition)
+	}
+
+	if chapters[2].PageSpread != "" || chapters[2].Properties != nil {
+		t.Errorf("chapter 2 = spread %q, properties %v; want none", chapters[2].PageSpread, chapters[2].Properties)
+	}
+}
+
+func TestChapter_Viewport(t *testing.T) {
+	book := openRenditionTestBook(t)
+	chapters := book.Chapters()
+
+	want := []Viewport{
+		{Width: 1200, Height: 1800}, // own <meta name="viewport">
+		{Width: 1000, Height: 1500}, // falls back to rendition:viewport
+		{Width: 640, Height: 900},   // non-numeric values ignored, px stripped
+	}
+	for i, ch := range chapters {
+		vp, err := ch.Viewport()
+		if err != nil {
+			t.Fatalf("chapter %d Viewport() error = %v", i, err)
+		}
+		if vp != want[i] {
+			t.Errorf("chapter %d Viewport() = %+v, want %+v", i, vp, want[i])
+		}
+	}
+
+	if _, err := (Chapter{}).Viewport(); !errors.Is(err, ErrInvalidChapter) {
+		t.Errorf("zero Chapter Viewport() error = %v, want ErrInvalidChapter", err)
+	}
+}
+
+func TestParseViewport(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Viewport
+		ok   bool
+	}{
+		{"", Viewport{}, false},
+		{"width=800", Viewport{Width: 800}, false},
+		{"width=800,height=600", Viewport{Width: 800, Height: 600}, true},
+		{" height = 600 ; width = 800 ", Viewport{Width: 800, Height: 600}, true},
+		{"width=-1, height=600", Viewport{Height: 600}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseViewport(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseViewport(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package epub
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Sources reported in StructuralSemantic.Source, in order of precedence.
+const (
+	SemanticSourceLandmarks = "landmarks"
+	SemanticSourceGuide     = "guide"
+	SemanticSourceContent   = "content"
+)
+
+// guideSemantics maps ePub 2 guide reference types to EPUB Structural
+// Semantics Vocabulary terms. Types not listed are used as-is (lower case,
+// without an "other." prefix).
+var guideSemantics = map[string]string{
+	"acknowledgements": "acknowledgments",
+	"bibliography":     "bibliography",
+	"colophon":         "colophon",
+	"copyright-page":   "copyright-page",
+	"cover":            "cover",
+	"dedication":       "dedication",
+	"epigraph":         "epigraph",
+	"foreword":         "foreword",
+	"glossary":         "glossary",
+	"index":            "index",
+	"loi":              "loi",
+	"lot":              "lot",
+	"notes":            "endnotes",
+	"preface":          "preface",
+	"text":             "bodymatter",
+	"title-page":       "titlepage",
+	"toc":              "toc",
+}
+
+// Guide returns the ePub 2 <guide> references with hrefs resolved to
+// ZIP-internal paths. Returns nil when the package has no guide.
+func (b *Book) Guide() []GuideReference {
+	if len(b.guide) == 0 {
+		return nil
+	}
+	out := make([]GuideReference, 0, len(b.guide))
+	for _, ref := range b.guide {
+		out = append(out, GuideReference{
+			Type:  strings.TrimSpace(ref.Type),
+			Title: strings.TrimSpace(ref.Title),
+			Href:  b.resolveOPFPath(strings.TrimSpace(ref.Href)),
+		})
+	}
+	return out
+}
+
+// StructuralSemantics returns the semantically marked locations of the
+// publication, unified across ePub versions: ePub 3 landmarks, ePub 2 guide
+// references (with their types mapped to the EPUB Structural Semantics
+// Vocabulary, e.g. "text" → "bodymatter"), and epub:type values on the
+// <body> and <section> elements of spine documents.
+//
+// Entries appear in that order of precedence. A type that points to the same
+// file as an earlier entry of the same type is omitted, so the first entry
+// of each type is the most reliable one. The result is computed on the first
+// call and cached.
+func (b *Book) StructuralSemantics() []StructuralSemantic {
+	if !b.semanticsBuilt {
+		b.semantics = b.buildStructuralSemantics()
+		b.semanticsBuilt = true
+	}
+	return append([]StructuralSemantic(nil), b.semantics...)
+}
+
+// FindStructuralSemantic returns the highest-precedence location of the given
+// EPUB Structural Semantics type, e.g. "bodymatter" for the start of the
+// text. Reports false if the publication marks no such location.
+func (b *Book) FindStructuralSemantic(typ string) (StructuralSemantic, bool) {
+	for _, s := range b.StructuralSemantics() {
+		if s.Type == typ {
+			return s, true
+		}
+	}
+	return StructuralSemantic{}, false
+}
+
+// buildStructuralSemantics collects and de-duplicates the entries of all
+// three sources.
+func (b *Book) buildStructuralSemantics() []StructuralSemantic {
+	spineMap := b.spineIndexMap()
+	var out []StructuralSemantic
+	seen := make(map[[2]string]bool) // (type, file)
+
+	add := func(typ, title, href, source string) {
+		key := [2]string{typ, hrefWithoutFragment(href)}
+		if typ == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		spineIdx := -1
+		if idx, ok := spineMap[hrefWithoutFragment(href)]; ok {
+			spineIdx = idx
+		}
+		out = append(out, StructuralSemantic{
+			Type:       typ,
+			Title:      title,
+			Href:       href,
+			SpineIndex: spineIdx,
+			Source:     source,
+		})
+	}
+
+	for _, lm := range b.landmarks {
+		for _, typ := range strings.Fields(lm.Type) {
+			add(typ, lm.Title, lm.Href, SemanticSourceLandmarks)
+		}
+	}
+	for _, ref := range b.Guide() {
+		add(guideSemanticType(ref.Type), ref.Title, ref.Href, SemanticSourceGuide)
+	}
+	for _, si := range b.spine {
+		href := b.resolveOPFPath(si.Href)
+		data, err := b.readContent(href)
+		if err != nil {
+			continue
+		}
+		for _, m := range contentSemantics(stripBOM(data)) {
+			target := href
+			if m.id != "" {
+				target += "#" + m.id
+			}
+			for _, typ := range strings.Fields(m.types) {
+				add(typ, m.title, target, SemanticSourceContent)
+			}
+		}
+	}
+	return out
+}
+
+// guideSemanticType maps a guide reference type to a structural semantics term.
+func guideSemanticType(typ string) string {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if t, ok := guideSemantics[typ]; ok {
+		return t
+	}
+	return strings.TrimPrefix(typ, "other.")
+}
+
+// semanticMark is an element carrying epub:type in a content document.
+type semanticMark struct {
+	types string
+	id    string
+	title string
+}
+
+// contentSemantics returns the epub:type marks on the <body> and <section>
+// elements of an XHTML document, in document order. The title of a section
+// is the text of its first heading.
+func contentSemantics(data []byte) []semanticMark {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	var marks []semanticMark
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Body || n.DataAtom == atom.Section) {
+			if types := navGetAttr(n, "epub:type"); strings.TrimSpace(types) != "" {
+				m := semanticMark{types: types}
+				if n.DataAtom == atom.Section {
+					m.id = navGetAttr(n, "id")
+				}
+				if h := firstHeading(n); h != nil {
+					m.title = strings.TrimSpace(collapseWhitespace(nodeTextContent(h)))
+				}
+				marks = append(marks, m)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return marks
+}
+
+// firstHeading returns the first <h1>–<h6> descendant of n, or nil.
+func firstHeading(n *html.Node) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && headingLevel(c.DataAtom) > 0 {
+			return c
+		}
+		if h := firstHeading(c); h != nil {
+			return h
+		}
+	}
+	return nil
+}
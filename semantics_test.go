@@ -0,0 +1,110 @@
+package epub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBook_Guide(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:guide</dc:identifier>
+    <dc:title>Guide</dc:title>
+  </metadata>
+  <manifest>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="cover"/>
+    <itemref idref="ch1"/>
+  </spine>
+  <guide>
+    <reference type="cover" title="Cover" href="cover.xhtml"/>
+    <reference type="text" title="Beginning" href="text/ch1.xhtml#start"/>
+    <reference type="Title-Page" title="Title Page" href="cover.xhtml"/>
+    <reference type="other.ms-coverimage-standard" title="Image" href="cover.jpg"/>
+  </guide>
+</package>`,
+		"OEBPS/cover.xhtml":    `<html><body><img src="cover.jpg" alt=""/></body></html>`,
+		"OEBPS/text/ch1.xhtml": `<html><body><p id="start">Text</p></body></html>`,
+	})
+
+	wantGuide := []GuideReference{
+		{Type: "cover", Title: "Cover", Href: "OEBPS/cover.xhtml"},
+		{Type: "text", Title: "Beginning", Href: "OEBPS/text/ch1.xhtml#start"},
+		{Type: "Title-Page", Title: "Title Page", Href: "OEBPS/cover.xhtml"},
+		{Type: "other.ms-coverimage-standard", Title: "Image", Href: "OEBPS/cover.jpg"},
+	}
+	if got := book.Guide(); !reflect.DeepEqual(got, wantGuide) {
+		t.Errorf("Guide() = %+v, want %+v", got, wantGuide)
+	}
+
+	wantSem := []StructuralSemantic{
+		{Type: "cover", Title: "Cover", Href: "OEBPS/cover.xhtml", SpineIndex: 0, Source: SemanticSourceGuide},
+		{Type: "bodymatter", Title: "Beginning", Href: "OEBPS/text/ch1.xhtml#start", SpineIndex: 1, Source: SemanticSourceGuide},
+		{Type: "titlepage", Title: "Title Page", Href: "OEBPS/cover.xhtml", SpineIndex: 0, Source: SemanticSourceGuide},
+		{Type: "ms-coverimage-standard", Title: "Image", Href: "OEBPS/cover.jpg", SpineIndex: -1, Source: SemanticSourceGuide},
+	}
+	if got := book.StructuralSemantics(); !reflect.DeepEqual(got, wantSem) {
+		t.Errorf("StructuralSemantics() = %+v, want %+v", got, wantSem)
+	}
+}
+
+func TestBook_StructuralSemantics_EPub3(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:semantics</dc:identifier>
+    <dc:title>Semantics</dc:title>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="title" href="title.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="title"/>
+    <itemref idref="ch1"/>
+  </spine>
+  <guide>
+    <reference type="text" title="Start" href="ch1.xhtml"/>
+  </guide>
+</package>`,
+		"OEBPS/nav.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<nav epub:type="toc"><ol><li><a href="ch1.xhtml">One</a></li></ol></nav>
+<nav epub:type="landmarks"><ol>
+  <li><a epub:type="bodymatter" href="ch1.xhtml">Start of Content</a></li>
+</ol></nav>
+</body></html>`,
+		"OEBPS/title.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body epub:type="frontmatter titlepage"><h1>Semantics</h1></body></html>`,
+		"OEBPS/ch1.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body epub:type="bodymatter">
+<section epub:type="chapter" id="c1"><h1>One</h1><p>Text</p></section>
+</body></html>`,
+	})
+
+	if lm := book.Landmarks(); len(lm) != 1 || lm[0].Type != "bodymatter" {
+		t.Fatalf("Landmarks() = %+v, want one bodymatter entry", lm)
+	}
+
+	want := []StructuralSemantic{
+		{Type: "bodymatter", Title: "Start of Content", Href: "OEBPS/ch1.xhtml", SpineIndex: 1, Source: SemanticSourceLandmarks},
+		{Type: "frontmatter", Title: "Semantics", Href: "OEBPS/title.xhtml", SpineIndex: 0, Source: SemanticSourceContent},
+		{Type: "titlepage", Title: "Semantics", Href: "OEBPS/title.xhtml", SpineIndex: 0, Source: SemanticSourceContent},
+		{Type: "chapter", Title: "One", Href: "OEBPS/ch1.xhtml#c1", SpineIndex: 1, Source: SemanticSourceContent},
+	}
+	if got := book.StructuralSemantics(); !reflect.DeepEqual(got, want) {
+		t.Errorf("StructuralSemantics() = %+v, want %+v", got, want)
+	}
+
+	start, ok := book.FindStructuralSemantic("bodymatter")
+	if !ok || start.Href != "OEBPS/ch1.xhtml" || start.Source != SemanticSourceLandmarks {
+		t.Errorf("FindStructuralSemantic(bodymatter) = %+v, %v", start, ok)
+	}
+	if _, ok := book.FindStructuralSemantic("index"); ok {
+		t.Error("FindStructuralSemantic(index) ok = true, want false")
+	}
+}
@@ -0,0 +1,603 @@
+package epub
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// SingleHTMLOptions configures WriteSingleHTML. The zero value writes every
+// spine chapter with its images and styles embedded and a generated table
+// of contents.
+type SingleHTMLOptions struct {
+	// ContentOnly writes only ContentChapters, skipping license and
+	// boilerplate pages.
+	ContentOnly bool
+
+	// NoTOC omits the table of contents <nav>.
+	NoTOC bool
+
+	// NoStyles omits the book's CSS.
+	NoStyles bool
+
+	// ExternalResources keeps image and font references as ZIP-internal
+	// paths instead of embedding them as data URIs.
+	ExternalResources bool
+}
+
+// singleHTMLMaxImportDepth limits nested CSS @import rules.
+const singleHTMLMaxImportDepth = 4
+
+// WriteSingleHTML writes the book as one self-contained HTML document. The
+// spine is concatenated in order, each chapter in a
+// <section id="chapter-N"> (N being its 1-based spine position). Element
+// ids are prefixed with the chapter anchor and links between chapters are
+// rewritten to in-page anchors, as in WriteMarkdown; links to chapters that
+// are not written (see ContentOnly) or to files outside the spine lose
+// their href. Images, audio, video, and fonts are embedded as data URIs,
+// including url() references in style attributes, and stylesheets as
+// <style> elements whose rules are scoped to the chapters that use them. A
+// <nav> generated from TOC precedes the chapters. Scripts and event handler
+// attributes are removed.
+func (b *Book) WriteSingleHTML(w io.Writer, opts SingleHTMLOptions) error {
+	chapters := b.Chapters()
+	if opts.ContentOnly {
+		chapters = b.ContentChapters()
+	}
+
+	s := &singleHTMLWriter{
+		book:       b,
+		opts:       opts,
+		dataURIs:   make(map[string]string),
+		written:    make(map[int]bool),
+		sheetClass: make(map[string]string),
+	}
+	for _, ch := range chapters {
+		s.written[ch.spineIndex()] = true
+	}
+
+	var body bytes.Buffer
+	if !opts.NoTOC {
+		if err := s.writeTOC(&body); err != nil {
+			return err
+		}
+	}
+	for _, ch := range chapters {
+		if err := s.writeChapter(&body, ch); err != nil {
+			return fmt.Errorf("epub: export %s to HTML: %w", ch.Href, err)
+		}
+	}
+
+	md := b.Metadata()
+	title := "Untitled"
+	if len(md.Titles) > 0 {
+		title = md.Titles[0]
+	}
+	var head strings.Builder
+	head.WriteString("<!DOCTYPE html>\n<html")
+	if len(md.Language) > 0 {
+		head.WriteString(` lang="` + html.EscapeString(md.Language[0]) + `"`)
+	}
+	head.WriteString(">\n<head>\n<meta charset=\"utf-8\"/>\n<title>" + html.EscapeString(title) + "</title>\n")
+	for _, css := range s.styles {
+		if css = strings.TrimSpace(css); css != "" {
+			// "</" could close the <style> element; "\/" is a CSS escape
+			// for "/".
+			css = strings.ReplaceAll(css, "</", `<\/`)
+			head.WriteString("<style>\n" + css + "\n</style>\n")
+		}
+	}
+	head.WriteString("</head>\n<body>\n")
+
+	if _, err := io.WriteString(w, head.String()); err != nil {
+		return err
+	}
+	if _, err := w.Write(body.Bytes()); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "</body>\n</html>\n")
+	return err
+}
+
+// singleHTMLWriter holds the state of a WriteSingleHTML call.
+type singleHTMLWriter struct {
+	book *Book
+	opts SingleHTMLOptions
+
+	// styles are the scoped <style> contents, in order of first use.
+	styles []string
+	// sheetClass maps a stylesheet's ZIP path to the class its rules are
+	// scoped to.
+	sheetClass map[string]string
+	// dataURIs caches embedded resources by ZIP path.
+	dataURIs map[string]string
+	// written holds the spine indexes of the chapters being written.
+	written map[int]bool
+}
+
+// writeTOC writes the table of contents <nav>.
+func (s *singleHTMLWriter) writeTOC(buf *bytes.Buffer) error {
+	items := s.tocList(s.book.TOC())
+	if items == nil {
+		return nil
+	}
+	nav := &html.Node{Type: html.ElementNode, Data: "nav", DataAtom: atom.Nav, Attr: []html.Attribute{
+		{Key: "id", Val: "toc"}, {Key: "role", Val: "doc-toc"},
+	}}
+	nav.AppendChild(items)
+	if err := html.Render(buf, nav); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	return nil
+}
+
+// tocList converts TOC entries to an <ol>, or nil when no entry links to a
+// written chapter.
+func (s *singleHTMLWriter) tocList(items []TOCItem) *html.Node {
+	ol := &html.Node{Type: html.ElementNode, Data: "ol", DataAtom: atom.Ol}
+	for _, item := range items {
+		children := s.tocList(item.Children)
+		target := ""
+		if i := item.SpineIndex; i >= 0 && s.written[i] {
+			target = "#" + chapterAnchor(i)
+			if _, frag, ok := strings.Cut(item.Href, "#"); ok && frag != "" {
+				target = "#" + fragmentAnchor(i, frag)
+			}
+		}
+		if target == "" && children == nil {
+			continue
+		}
+
+		li := &html.Node{Type: html.ElementNode, Data: "li", DataAtom: atom.Li}
+		label := &html.Node{Type: html.ElementNode, Data: "span", DataAtom: atom.Span}
+		if target != "" {
+			label = &html.Node{Type: html.ElementNode, Data: "a", DataAtom: atom.A, Attr: []html.Attribute{{Key: "href", Val: target}}}
+		}
+		label.AppendChild(&html.Node{Type: html.TextNode, Data: item.Title})
+		li.AppendChild(label)
+		if children != nil {
+			li.AppendChild(children)
+		}
+		ol.AppendChild(li)
+	}
+	if ol.FirstChild == nil {
+		return nil
+	}
+	return ol
+}
+
+// stripFragment returns href without its fragment.
+func stripFragment(href string) string {
+	p, _, _ := strings.Cut(href, "#")
+	return p
+}
+
+// writeChapter writes a chapter as a <section>.
+func (s *singleHTMLWriter) writeChapter(buf *bytes.Buffer, ch Chapter) error {
+	data, err := ch.RawContent()
+	if err != nil {
+		return err
+	}
+	doc, err := html.Parse(bytes.NewReader(normalizeSelfClosingSkipTags(data)))
+	if err != nil {
+		return err
+	}
+
+	index := ch.spineIndex()
+	anchor := chapterAnchor(index)
+	classes := []string{"chapter"}
+
+	if !s.opts.NoStyles {
+		var inline []string
+		s.collectStyles(doc, ch.Href, &classes, &inline)
+		for _, css := range inline {
+			s.styles = append(s.styles, s.scopeCSS(css, ch.Href, "#"+anchor, 0))
+		}
+	}
+
+	body := findElement(doc, atom.Body)
+	if body == nil {
+		body = doc
+	}
+	if class := strings.Fields(navGetAttr(body, "class")); len(class) > 0 {
+		classes = append(classes, class...)
+	}
+	cleanNode(body)
+	s.rewriteNode(body, ch.Href, index)
+
+	section := &html.Node{Type: html.ElementNode, Data: "section", DataAtom: atom.Section, Attr: []html.Attribute{
+		{Key: "id", Val: anchor}, {Key: "class", Val: strings.Join(classes, " ")},
+	}}
+	for _, a := range body.Attr {
+		if a.Key == "lang" || a.Key == "dir" || (a.Namespace == "xml" && a.Key == "lang") || a.Key == "xml:lang" {
+			section.Attr = append(section.Attr, html.Attribute{Key: strings.TrimPrefix(a.Key, "xml:"), Val: a.Val})
+		}
+	}
+	for c := body.FirstChild; c != nil; {
+		next := c.NextSibling
+		body.RemoveChild(c)
+		section.AppendChild(c)
+		c = next
+	}
+	if err := html.Render(buf, section); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	return nil
+}
+
+// collectStyles gathers the stylesheets linked by doc, the document at
+// file, adding the class of each to classes, and the contents of its
+// <style> elements to inline.
+func (s *singleHTMLWriter) collectStyles(n *html.Node, file string, classes, inline *[]string) {
+	if n.Type == html.ElementNode {
+		switch n.DataAtom {
+		case atom.Link:
+			rel := strings.Fields(strings.ToLower(navGetAttr(n, "rel")))
+			href := navGetAttr(n, "href")
+			if href == "" || hasURIScheme(href) || !containsString(rel, "stylesheet") || containsString(rel, "alternate") {
+				break
+			}
+			if p := resolveRelativePath(file, stripFragment(href)); p != "" {
+				if class := s.stylesheetClass(p); class != "" {
+					*classes = append(*classes, class)
+				}
+			}
+		case atom.Style:
+			*inline = append(*inline, nodeTextContent(n))
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		s.collectStyles(c, file, classes, inline)
+	}
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// stylesheetClass returns the class that the rules of the stylesheet at
+// the ZIP path p are scoped to, adding its scoped CSS to the document on
+// first use. It returns "" when the stylesheet cannot be read.
+func (s *singleHTMLWriter) stylesheetClass(p string) string {
+	if class, ok := s.sheetClass[p]; ok {
+		return class
+	}
+	data, err := s.book.readContent(p)
+	if err != nil {
+		s.book.warnings = append(s.book.warnings, fmt.Sprintf("stylesheet %s: %v", p, err))
+		s.sheetClass[p] = ""
+		return ""
+	}
+	class := "css-" + strconv.Itoa(len(s.sheetClass)+1)
+	s.sheetClass[p] = class
+	s.styles = append(s.styles, s.scopeCSS(string(stripBOM(data)), p, "."+class, 0))
+	return class
+}
+
+// rewriteNode prefixes element ids with the chapter anchor, rewrites links
+// to in-page anchors, and embeds images and media, in the subtree of n from
+// the document at file.
+func (s *singleHTMLWriter) rewriteNode(n *html.Node, file string, index int) {
+	if n.Type == html.ElementNode {
+		for i, a := range n.Attr {
+			switch {
+			case a.Key == "id" && a.Namespace == "":
+				n.Attr[i].Val = fragmentAnchor(index, strings.TrimSpace(a.Val))
+			case isMediaElement(n) && matchAttr(a, "", "src"):
+				n.Attr[i].Val = s.resourceURI(file, a.Val)
+			case n.DataAtom == atom.Video && matchAttr(a, "", "poster"):
+				n.Attr[i].Val = s.resourceURI(file, a.Val)
+			case n.DataAtom == atom.Image && (matchAttr(a, "xlink", "href") || matchAttr(a, "", "href")):
+				n.Attr[i].Val = s.resourceURI(file, a.Val)
+			case matchAttr(a, "xlink", "href") || matchAttr(a, "", "href"):
+				if target, ok := s.linkTarget(file, a.Val); ok {
+					n.Attr[i].Val = target
+				} else {
+					n.Attr[i].Key = ""
+				}
+			case matchAttr(a, "", "style"):
+				n.Attr[i].Val = s.cssURLs(a.Val, file)
+			case a.Key == "srcset":
+				// Candidates cannot be embedded reliably; src is the fallback.
+				n.Attr[i].Key = ""
+			}
+		}
+		attrs := n.Attr[:0]
+		for _, a := range n.Attr {
+			if a.Key != "" {
+				attrs = append(attrs, a)
+			}
+		}
+		n.Attr = attrs
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		s.rewriteNode(c, file, index)
+	}
+}
+
+// isMediaElement reports whether n is an element whose src attribute
+// references an image, audio, or video resource.
+func isMediaElement(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Img, atom.Audio, atom.Video, atom.Source, atom.Track:
+		return true
+	}
+	return false
+}
+
+// linkTarget returns the in-page anchor for href, found in the document at
+// file, or href itself when it is an absolute URI. It reports false when
+// the link cannot resolve within the single document: it points to a
+// chapter that is not written or to a file outside the spine.
+func (s *singleHTMLWriter) linkTarget(file, href string) (string, bool) {
+	href = strings.TrimSpace(href)
+	if href == "" || hasURIScheme(href) || strings.HasPrefix(href, "//") {
+		return href, true
+	}
+	target := file
+	if p := stripFragment(href); p != "" {
+		target = resolveRelativePath(file, p)
+	}
+	if i := s.book.spineIndexOf(target); i < 0 || !s.written[i] {
+		return "", false
+	}
+	return internalLinkTarget(s.book, file, href), true
+}
+
+// resourceURI returns the reference to use for the resource ref, found in
+// the document at file: a data URI, or the ZIP-internal path with
+// ExternalResources or when the resource cannot be read.
+func (s *singleHTMLWriter) resourceURI(file, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") || hasURIScheme(ref) || strings.HasPrefix(ref, "//") {
+		return ref
+	}
+	p := resolveRelativePath(file, stripFragment(ref))
+	if p == "" {
+		return ref
+	}
+	if s.opts.ExternalResources {
+		return p
+	}
+	if uri, ok := s.dataURIs[p]; ok {
+		return uri
+	}
+
+	data, err := s.book.ReadFile(p)
+	if err != nil {
+		s.book.warnings = append(s.book.warnings, fmt.Sprintf("resource %s: %v", p, err))
+		s.dataURIs[p] = p
+		return p
+	}
+	uri := "data:" + s.book.resourceMediaType(p, data) + ";base64," + base64.StdEncoding.EncodeToString(data)
+	s.dataURIs[p] = uri
+	return uri
+}
+
+// resourceMediaType returns the media type of the resource at the ZIP path
+// p: its manifest media type, or else one derived from its extension or
+// content.
+func (b *Book) resourceMediaType(p string, data []byte) string {
+	for _, item := range b.manifestByHref {
+		if item.MediaType != "" && b.resolveOPFPath(item.Href) == p {
+			return item.MediaType
+		}
+	}
+	if mt := mime.TypeByExtension(strings.ToLower(path.Ext(p))); mt != "" {
+		return mt
+	}
+	return http.DetectContentType(data)
+}
+
+// cssCommentPattern matches CSS comments.
+var cssCommentPattern = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// cssURLPattern matches a url() reference; the URL is in submatch 2.
+var cssURLPattern = regexp.MustCompile(`url\(\s*(['"]?)([^'")]*)(['"]?)\s*\)`)
+
+// scopeCSS rewrites the stylesheet css, located at the ZIP path file, so
+// that its rules apply only within elements matching scope: every selector
+// is prefixed with scope, and html, body, and :root selectors are replaced
+// by it. Rules inside @media, @supports, and @layer are scoped; other
+// at-rules such as @font-face and @keyframes are kept. @import rules are
+// replaced by the scoped imported stylesheet. url() references are
+// embedded.
+func (s *singleHTMLWriter) scopeCSS(css, file, scope string, depth int) string {
+	css = cssCommentPattern.ReplaceAllString(css, "")
+	var out strings.Builder
+	for {
+		css = strings.TrimSpace(css)
+		if css == "" {
+			break
+		}
+		end := cssIndexAny(css, "{;")
+		if end < 0 {
+			break
+		}
+		prelude := strings.TrimSpace(css[:end])
+		lower := strings.ToLower(prelude)
+
+		if css[end] == ';' {
+			css = css[end+1:]
+			if strings.HasPrefix(lower, "@import") && depth < singleHTMLMaxImportDepth {
+				if m := cssImportTarget(prelude); m != "" && !hasURIScheme(m) {
+					if p := resolveRelativePath(file, m); p != "" {
+						if data, err := s.book.readContent(p); err == nil {
+							out.WriteString(s.scopeCSS(string(stripBOM(data)), p, scope, depth+1) + "\n")
+						}
+					}
+				}
+			}
+			continue
+		}
+
+		closing := cssMatchingBrace(css, end)
+		block := css[end+1 : closing]
+		css = css[min(closing+1, len(css)):]
+		switch {
+		case strings.HasPrefix(lower, "@media"), strings.HasPrefix(lower, "@supports"), strings.HasPrefix(lower, "@layer"), strings.HasPrefix(lower, "@container"):
+			out.WriteString(prelude + " {\n" + s.scopeCSS(block, file, scope, depth) + "}\n")
+		case strings.HasPrefix(lower, "@"):
+			out.WriteString(prelude + " {" + s.cssURLs(block, file) + "}\n")
+		default:
+			var selectors []string
+			for _, sel := range cssSplitSelectors(prelude) {
+				if sel = scopeSelector(sel, scope); sel != "" {
+					selectors = append(selectors, sel)
+				}
+			}
+			if len(selectors) > 0 {
+				out.WriteString(strings.Join(selectors, ", ") + " {" + s.cssURLs(block, file) + "}\n")
+			}
+		}
+	}
+	return out.String()
+}
+
+// cssURLs embeds the url() references of a declaration block from the
+// stylesheet at file.
+func (s *singleHTMLWriter) cssURLs(block, file string) string {
+	return cssURLPattern.ReplaceAllStringFunc(block, func(m string) string {
+		ref := strings.TrimSpace(cssURLPattern.FindStringSubmatch(m)[2])
+		if ref == "" || strings.HasPrefix(ref, "#") || hasURIScheme(ref) {
+			return m
+		}
+		return `url("` + s.resourceURI(file, ref) + `")`
+	})
+}
+
+// cssImportTarget returns the URL of an @import rule.
+func cssImportTarget(rule string) string {
+	rest := strings.TrimSpace(rule[len("@import"):])
+	if m := cssURLPattern.FindStringSubmatch(rest); m != nil && strings.HasPrefix(rest, m[0]) {
+		return strings.TrimSpace(m[2])
+	}
+	if len(rest) > 1 && (rest[0] == '"' || rest[0] == '\'') {
+		if i := strings.IndexByte(rest[1:], rest[0]); i >= 0 {
+			return rest[1 : i+1]
+		}
+	}
+	return ""
+}
+
+// cssIndexAny returns the index of the first byte of chars in css outside
+// strings and parentheses, or -1.
+func cssIndexAny(css, chars string) int {
+	var quote byte
+	parens := 0
+	for i := 0; i < len(css); i++ {
+		c := css[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			parens++
+		case c == ')':
+			parens--
+		case parens <= 0 && strings.IndexByte(chars, c) >= 0:
+			return i
+		}
+	}
+	return -1
+}
+
+// cssMatchingBrace returns the index of the '}' closing the '{' at open,
+// or len(css) when it is unterminated.
+func cssMatchingBrace(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); {
+		j := cssIndexAny(css[i:], "{}")
+		if j < 0 {
+			break
+		}
+		i += j
+		if css[i] == '{' {
+			depth++
+		} else if depth--; depth == 0 {
+			return i
+		}
+		i++
+	}
+	return len(css)
+}
+
+// cssSplitSelectors splits a selector list at top-level commas.
+func cssSplitSelectors(prelude string) []string {
+	var selectors []string
+	for {
+		i := cssIndexAny(prelude, ",")
+		if i < 0 {
+			return append(selectors, prelude)
+		}
+		selectors = append(selectors, prelude[:i])
+		prelude = prelude[i+1:]
+	}
+}
+
+// scopeSelector scopes a single selector to scope. Leading html, :root,
+// and body type selectors are replaced by scope.
+func scopeSelector(sel, scope string) string {
+	sel = strings.TrimSpace(sel)
+	if sel == "" {
+		return ""
+	}
+	rest, ok := cutTypeSelector(sel, "html")
+	if !ok {
+		rest, ok = cutTypeSelector(sel, ":root")
+	}
+	switch {
+	case ok:
+		if trimmed := strings.TrimLeft(rest, " \t\n>"); trimmed != rest || rest == "" {
+			// A descendant of the root: "html body p" or "html > p".
+			if body, ok := cutTypeSelector(trimmed, "body"); ok {
+				rest = body
+			} else if trimmed != "" {
+				return scope + " " + trimmed
+			} else {
+				return scope
+			}
+		}
+	default:
+		if rest, ok = cutTypeSelector(sel, "body"); !ok {
+			return scope + " " + sel
+		}
+	}
+	return scope + rest
+}
+
+// cutTypeSelector reports whether sel starts with the type selector name,
+// and returns the rest of sel.
+func cutTypeSelector(sel, name string) (string, bool) {
+	if len(sel) < len(name) || !strings.EqualFold(sel[:len(name)], name) {
+		return sel, false
+	}
+	rest := sel[len(name):]
+	if rest != "" && !strings.ContainsRune(" \t\n>+~.#:[", rune(rest[0])) {
+		return sel, false
+	}
+	return rest, true
+}
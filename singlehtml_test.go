@@ -0,0 +1,191 @@
+package epub
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func openSingleHTMLTestBook(t *testing.T) *Book {
+	t.Helper()
+	return openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:single</dc:identifier>
+    <dc:title>Single &amp; Whole</dc:title>
+    <dc:language>en</dc:language>
+  </metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="css" href="styles/book.css" media-type="text/css"/>
+    <item id="img" href="images/dot.png" media-type="image/png"/>
+    <item id="clip" href="media/clip.mp4" media-type="video/mp4"/>
+    <item id="ch1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="text/ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="license" href="text/license.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx"><itemref idref="ch1"/><itemref idref="ch2"/><itemref idref="license"/></spine>
+</package>`,
+		"OEBPS/toc.ncx": `<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1"><navMap>
+<navPoint id="n1" playOrder="1"><navLabel><text>One</text></navLabel><content src="text/ch1.xhtml"/>
+  <navPoint id="n2" playOrder="2"><navLabel><text>Two</text></navLabel><content src="text/ch2.xhtml#two"/></navPoint>
+</navPoint>
+</navMap></ncx>`,
+		"OEBPS/styles/book.css": `@import "base.css";
+/* comment { } */
+body { margin: 0 }
+html body p.note, h1 { color: red }
+@media screen { p { font-size: 1em } }
+@font-face { font-family: X; src: url(../fonts/x.woff) }`,
+		"OEBPS/styles/base.css": `em { font-style: italic }`,
+		"OEBPS/images/dot.png":  "PNG",
+		"OEBPS/media/clip.mp4":  "MP4",
+		"OEBPS/text/ch1.xhtml": `<html><head><link rel="stylesheet" href="../styles/book.css"/>
+<style>p { color: blue }</style><script>alert(1)</script></head>
+<body class="intro"><h1 id="one">One</h1>
+<p onclick="x()">See <a href="ch2.xhtml#two">two</a> or <a href="#one">top</a>.</p>
+<p><img src="../images/dot.png" alt="dot"/></p></body></html>`,
+		"OEBPS/text/ch2.xhtml": `<html><head><link rel="stylesheet" href="../styles/book.css"/></head>
+<body><h1 id="two">Two</h1><p><a href="https://example.com/">Web</a></p>
+<p style="background: url('../images/dot.png')"><a href="../images/dot.png">Picture</a> and <a href="license.xhtml">license</a>.</p>
+<video poster="../images/dot.png"><source src="../media/clip.mp4" type="video/mp4"/></video></body></html>`,
+		"OEBPS/text/license.xhtml": `<html><body><p>*** START OF THE PROJECT GUTENBERG LICENSE ***</p></body></html>`,
+	})
+}
+
+func TestBook_WriteSingleHTML(t *testing.T) {
+	book := openSingleHTMLTestBook(t)
+	var buf bytes.Buffer
+	if err := book.WriteSingleHTML(&buf, SingleHTMLOptions{}); err != nil {
+		t.Fatalf("WriteSingleHTML() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>\n<html lang=\"en\">",
+		"<title>Single &amp; Whole</title>",
+		`<nav id="toc" role="doc-toc"><ol><li><a href="#chapter-1">One</a><ol><li><a href="#chapter-2-two">Two</a></li></ol></li></ol></nav>`,
+		`<section id="chapter-1" class="chapter css-1 intro">`,
+		`<section id="chapter-2" class="chapter css-1">`,
+		`<h1 id="chapter-1-one">One</h1>`,
+		`<a href="#chapter-2-two">two</a>`,
+		`<a href="#chapter-1-one">top</a>`,
+		`<a href="https://example.com/">Web</a>`,
+		`<img src="data:image/png;base64,UE5H" alt="dot"/>`,
+		".css-1 em { font-style: italic }",
+		".css-1 { margin: 0 }",
+		".css-1 p.note, .css-1 h1 { color: red }",
+		"@media screen {\n.css-1 p { font-size: 1em }",
+		"@font-face { font-family: X; src: url(\"OEBPS/fonts/x.woff\") }",
+		"#chapter-1 p { color: blue }",
+		`<p style="background: url(&#34;data:image/png;base64,UE5H&#34;)"><a>Picture</a> and <a href="#chapter-3">license</a>.</p>`,
+		`<video poster="data:image/png;base64,UE5H"><source src="data:video/mp4;base64,TVA0" type="video/mp4"/></video>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteSingleHTML() output missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"<script", "alert(1)", "onclick", "comment"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("WriteSingleHTML() output contains %q", unwanted)
+		}
+	}
+	// The shared stylesheet is embedded once.
+	if n := strings.Count(got, "{ color: red }"); n != 1 {
+		t.Errorf("stylesheet embedded %d times, want 1", n)
+	}
+	if t.Failed() {
+		t.Logf("output:\n%s", got)
+	}
+}
+
+func TestBook_WriteSingleHTML_Options(t *testing.T) {
+	book := openSingleHTMLTestBook(t)
+	var buf bytes.Buffer
+	opts := SingleHTMLOptions{NoTOC: true, NoStyles: true, ExternalResources: true}
+	if err := book.WriteSingleHTML(&buf, opts); err != nil {
+		t.Fatalf("WriteSingleHTML() error = %v", err)
+	}
+	got := buf.String()
+	if strings.Contains(got, "<nav") || strings.Contains(got, "<style") {
+		t.Errorf("WriteSingleHTML() wrote a TOC or styles:\n%s", got)
+	}
+	if !strings.Contains(got, `<img src="OEBPS/images/dot.png" alt="dot"/>`) {
+		t.Errorf("WriteSingleHTML() did not keep the image path:\n%s", got)
+	}
+}
+
+func TestBook_WriteSingleHTML_ContentOnly(t *testing.T) {
+	book := openSingleHTMLTestBook(t)
+	var buf bytes.Buffer
+	if err := book.WriteSingleHTML(&buf, SingleHTMLOptions{ContentOnly: true}); err != nil {
+		t.Fatalf("WriteSingleHTML() error = %v", err)
+	}
+	got := buf.String()
+	if strings.Contains(got, "chapter-3") || strings.Contains(got, "GUTENBERG") {
+		t.Errorf("WriteSingleHTML() wrote or linked the license page:\n%s", got)
+	}
+	if !strings.Contains(got, "<a>license</a>") {
+		t.Errorf("WriteSingleHTML() did not unwrap the link to the license page:\n%s", got)
+	}
+}
+
+func TestBook_WriteSingleHTML_HostileStylesheet(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:hostile</dc:identifier>
+    <dc:title>Hostile</dc:title>
+  </metadata>
+  <manifest>
+    <item id="css" href="evil.css" media-type="text/css"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`,
+		"OEBPS/evil.css": `p::after { content: "</style><script>alert(1)</script>" }
+h1::before { content: "</STYLE >" }`,
+		"OEBPS/ch1.xhtml": `<html><head><link rel="stylesheet" href="evil.css"/></head><body><p>Text.</p></body></html>`,
+	})
+	var buf bytes.Buffer
+	if err := book.WriteSingleHTML(&buf, SingleHTMLOptions{}); err != nil {
+		t.Fatalf("WriteSingleHTML() error = %v", err)
+	}
+	got := buf.String()
+	doc, err := html.Parse(strings.NewReader(got))
+	if err != nil {
+		t.Fatalf("html.Parse() error = %v", err)
+	}
+	if findElement(doc, atom.Script) != nil {
+		t.Errorf("WriteSingleHTML() let a stylesheet inject a <script>:\n%s", got)
+	}
+	if !strings.Contains(got, `content: "<\/style><script>alert(1)<\/script>"`) {
+		t.Errorf("WriteSingleHTML() did not escape the stylesheet:\n%s", got)
+	}
+}
+
+func TestScopeSelector(t *testing.T) {
+	tests := map[string]string{
+		"p":           ".s p",
+		"body":        ".s",
+		"body.dark p": ".s.dark p",
+		"html":        ".s",
+		"html > body": ".s",
+		"html p":      ".s p",
+		":root":       ".s",
+		"bodytext":    ".s bodytext",
+		"a:hover":     ".s a:hover",
+		"html.js .x":  ".s.js .x",
+		"  h1 + p  ":  ".s h1 + p",
+	}
+	for sel, want := range tests {
+		if got := scopeSelector(sel, ".s"); got != want {
+			t.Errorf("scopeSelector(%q) = %q, want %q", sel, got, want)
+		}
+	}
+}
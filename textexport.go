@@ -0,0 +1,284 @@
+package epub
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// TextExportOptions configures WriteText. The zero value writes every
+// chapter without wrapping, one paragraph per line.
+type TextExportOptions struct {
+	// Width wraps lines at this many columns. East Asian wide and fullwidth
+	// characters count as two columns, and lines of such text may break
+	// between any two characters. Zero disables wrapping.
+	Width int
+
+	// ParagraphSpacing is the number of blank lines between paragraphs.
+	ParagraphSpacing int
+
+	// RemoveLicense skips Project Gutenberg license pages and other
+	// boilerplate pages, writing only ContentChapters.
+	RemoveLicense bool
+
+	// RemoveFrontMatter skips chapters classified (see ClassifyChapters) as
+	// cover, title page, copyright, dedication, or table of contents, and
+	// unclassified chapters before the first body chapter. Unclassified
+	// chapters after it, such as generic back matter, are kept.
+	RemoveFrontMatter bool
+
+	// NoTitleBlock omits the title block.
+	NoTitleBlock bool
+}
+
+// WriteText writes the book to w as a single plain-text document: a title
+// block built from Metadata (title, subtitle, and authors), followed by the
+// text of each chapter (see TextContent). A chapter with a title (taken from
+// the TOC, see Chapter.Title) starts with that title as an underlined
+// heading; a first line repeating the title is dropped.
+func (b *Book) WriteText(w io.Writer, opts TextExportOptions) error {
+	spine := b.spineIndexMap()
+	bodyStart := -1
+	if opts.RemoveFrontMatter {
+		b.ClassifyChapters()
+		for _, ch := range b.chapters {
+			if ch.Role == ChapterRoleBody {
+				bodyStart = spine[ch.Href]
+				break
+			}
+		}
+	}
+	chapters := b.Chapters()
+	if opts.RemoveLicense {
+		chapters = b.ContentChapters()
+	}
+
+	var sections []string
+	if !opts.NoTitleBlock {
+		if block := textTitleBlock(b.Metadata(), opts.Width); block != "" {
+			sections = append(sections, block)
+		}
+	}
+	sep := "\n" + strings.Repeat("\n", max(opts.ParagraphSpacing, 0))
+	for _, ch := range chapters {
+		if opts.RemoveFrontMatter && (isFrontMatterRole(ch.Role) ||
+			ch.Role == ChapterRoleUnknown && spine[ch.Href] < bodyStart) {
+			continue
+		}
+		text, err := ch.TextContent()
+		if err != nil {
+			return fmt.Errorf("epub: export %s to text: %w", ch.Href, err)
+		}
+
+		var paragraphs []string
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				paragraphs = append(paragraphs, wrapText(line, opts.Width))
+			}
+		}
+		title := strings.Join(strings.Fields(ch.Title), " ")
+		if len(paragraphs) > 0 && title != "" && strings.EqualFold(strings.Join(strings.Fields(paragraphs[0]), " "), title) {
+			paragraphs = paragraphs[1:]
+		}
+
+		var section []string
+		if title != "" {
+			section = append(section, textHeading(title, "-", opts.Width))
+		}
+		if len(paragraphs) > 0 {
+			section = append(section, strings.Join(paragraphs, sep))
+		}
+		if len(section) > 0 {
+			sections = append(sections, strings.Join(section, "\n\n"))
+		}
+	}
+
+	if len(sections) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w, strings.Join(sections, "\n\n\n")+"\n")
+	return err
+}
+
+// isFrontMatterRole reports whether role is removed by
+// TextExportOptions.RemoveFrontMatter wherever the chapter appears.
+func isFrontMatterRole(role ChapterRole) bool {
+	switch role {
+	case ChapterRoleCover, ChapterRoleTitlePage, ChapterRoleCopyright, ChapterRoleDedication, ChapterRoleTOC:
+		return true
+	}
+	return false
+}
+
+// textTitleBlock returns the title block for md: the title underlined with
+// "=", the subtitle, and a "by" line naming the authors.
+func textTitleBlock(md Metadata, lineWidth int) string {
+	title := md.MainTitle()
+	var lines []string
+	if title != "" {
+		lines = append(lines, textHeading(title, "=", lineWidth))
+	}
+	if sub := md.Subtitle(); sub != "" && sub != title {
+		lines = append(lines, wrapText(sub, lineWidth))
+	}
+	var names []string
+	for _, a := range md.Authors {
+		if name := strings.TrimSpace(a.Name); name != "" && (a.Role == "" || a.Role == RoleAuthor) {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		lines = append(lines, wrapText("by "+strings.Join(names, ", "), lineWidth))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textHeading returns title wrapped to lineWidth and underlined with rule,
+// as wide as its longest line.
+func textHeading(title, rule string, lineWidth int) string {
+	title = wrapText(title, lineWidth)
+	longest := 0
+	for _, line := range strings.Split(title, "\n") {
+		longest = max(longest, textWidth(line))
+	}
+	return title + "\n" + strings.Repeat(rule, longest)
+}
+
+// textWidth returns the display width of s in columns.
+func textWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// runeWidth returns the display width of r: two columns for East Asian
+// wide and fullwidth characters, zero for combining marks and format
+// characters, and one otherwise.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// isWideRune reports whether r is an East Asian wide or fullwidth
+// character, between which lines may break.
+func isWideRune(r rune) bool {
+	return runeWidth(r) == 2
+}
+
+// noBreakBefore holds closing punctuation that must not start a line.
+const noBreakBefore = ",.;:!?)]}%、。，．：；？！）］｝」』】〕〉》〟’”…‥ー々ゝゞぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ・〜"
+
+// noBreakAfter holds opening punctuation that must not end a line.
+const noBreakAfter = "([{（［｛「『【〔〈《〝‘“"
+
+// wrapText wraps a paragraph to lines of at most lineWidth columns,
+// breaking at spaces and between East Asian wide characters. Closing
+// punctuation is kept with the preceding text and opening punctuation with
+// the following text; words longer than a line are split. A lineWidth of
+// zero or less returns s unchanged.
+func wrapText(s string, lineWidth int) string {
+	if lineWidth <= 0 || textWidth(s) <= lineWidth {
+		return s
+	}
+
+	var lines []string
+	var line strings.Builder
+	lineW := 0
+	for _, seg := range textSegments(s) {
+		segW := textWidth(seg.text)
+		gap := 0
+		if seg.space && lineW > 0 {
+			gap = 1
+		}
+		if lineW > 0 && lineW+gap+segW > lineWidth {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineW, gap = 0, 0
+		}
+		if gap > 0 {
+			line.WriteByte(' ')
+		}
+		// Split a segment longer than a line.
+		for _, r := range seg.text {
+			rw := runeWidth(r)
+			if lineW > 0 && lineW+gap+rw > lineWidth {
+				lines = append(lines, line.String())
+				line.Reset()
+				lineW, gap = 0, 0
+			}
+			line.WriteRune(r)
+			lineW += gap + rw
+			gap = 0
+		}
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textSegment is an unbreakable run of text; space reports whether it
+// follows a space.
+type textSegment struct {
+	text  string
+	space bool
+}
+
+// textSegments splits s into unbreakable segments: words, and single East
+// Asian wide characters, joined with adjacent punctuation that must not be
+// separated from them.
+func textSegments(s string) []textSegment {
+	var segs []textSegment
+	var cur []rune
+	space, glueNext := false, false
+	flush := func() {
+		if len(cur) > 0 {
+			segs = append(segs, textSegment{text: string(cur), space: space})
+			cur, space = nil, false
+		}
+	}
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+			space, glueNext = len(segs) > 0, false
+			continue
+		case strings.ContainsRune(noBreakBefore, r) && len(cur) == 0 && !space && len(segs) > 0:
+			// Attach to the previous segment.
+			last := &segs[len(segs)-1]
+			last.text += string(r)
+			continue
+		case strings.ContainsRune(noBreakBefore, r):
+			cur = append(cur, r)
+			continue
+		case glueNext:
+			cur = append(cur, r)
+		case isWideRune(r):
+			flush()
+			cur = append(cur, r)
+		case len(cur) > 0 && isWideRune(cur[len(cur)-1]):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+		glueNext = strings.ContainsRune(noBreakAfter, r)
+		if isWideRune(r) && !glueNext {
+			flush()
+		}
+	}
+	flush()
+	return segs
+}
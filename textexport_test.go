@@ -0,0 +1,129 @@
+package epub
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{"no wrap", "The quick brown fox", 0, "The quick brown fox"},
+		{"fits", "The quick brown fox", 19, "The quick brown fox"},
+		{"spaces", "The quick brown fox jumps over the lazy dog.", 15, "The quick brown\nfox jumps over\nthe lazy dog."},
+		{"long word", "a supercalifragilistic word", 8, "a\nsupercal\nifragili\nstic\nword"},
+		{"wide characters", "日本語の文章です", 6, "日本語\nの文章\nです"},
+		{"closing punctuation", "これは、テストです。", 8, "これは、\nテストで\nす。"},
+		{"opening punctuation", "彼は「はい」と言った", 6, "彼は\n「は\nい」と\n言った"},
+		{"mixed", "Go言語は simple です", 10, "Go言語は\nsimple で\nす"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.s, tt.width); got != tt.want {
+				t.Errorf("wrapText(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := map[string]int{
+		"abc":   3,
+		"日本":    4,
+		"ＡＢ":    4,
+		"Café": 4,
+		"ｱｲ":    2,
+	}
+	for s, want := range tests {
+		if got := textWidth(s); got != want {
+			t.Errorf("textWidth(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func openTextExportTestBook(t *testing.T) *Book {
+	t.Helper()
+	return openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:text-export</dc:identifier>
+    <dc:title>A Short Book</dc:title>
+    <dc:creator>Jane Doe</dc:creator>
+    <dc:creator>John Roe</dc:creator>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="copyright" href="copyright.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="afterword" href="afterword.xhtml" media-type="application/xhtml+xml"/>
+    <item id="license" href="license.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="copyright"/>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+    <itemref idref="afterword"/>
+    <itemref idref="license"/>
+  </spine>
+</package>`,
+		"OEBPS/nav.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><nav epub:type="toc"><ol>
+<li><a href="ch1.xhtml">Chapter One</a></li>
+<li><a href="ch2.xhtml">Chapter Two</a></li>
+</ol></nav></body></html>`,
+		"OEBPS/copyright.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body epub:type="copyright-page"><p>Copyright 2024.</p></body></html>`,
+		"OEBPS/ch1.xhtml":       `<html><body><h1>Chapter One</h1><p>It was a dark and stormy night.</p><p>The end.</p></body></html>`,
+		"OEBPS/ch2.xhtml":       `<html><body><p>Another chapter.</p></body></html>`,
+		"OEBPS/afterword.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body epub:type="backmatter"><p>Afterword.</p></body></html>`,
+		"OEBPS/license.xhtml":   `<html><body><p>End of the Project Gutenberg EBook. Project Gutenberg License: please read this before you distribute or use this work.</p></body></html>`,
+	})
+}
+
+func TestBook_WriteText(t *testing.T) {
+	tests := []struct {
+		name string
+		opts TextExportOptions
+		want string
+	}{
+		{
+			name: "wrapped",
+			opts: TextExportOptions{Width: 20, ParagraphSpacing: 1, RemoveLicense: true, RemoveFrontMatter: true},
+			want: "A Short Book\n============\nby Jane Doe, John\nRoe\n\n\n" +
+				"Chapter One\n-----------\n\nIt was a dark and\nstormy night.\n\nThe end.\n\n\n" +
+				"Chapter Two\n-----------\n\nAnother chapter.\n\n\nAfterword.\n",
+		},
+		{
+			name: "no title block",
+			opts: TextExportOptions{NoTitleBlock: true, RemoveLicense: true},
+			want: "Copyright 2024.\n\n\n" +
+				"Chapter One\n-----------\n\nIt was a dark and stormy night.\nThe end.\n\n\n" +
+				"Chapter Two\n-----------\n\nAnother chapter.\n\n\nAfterword.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := openTextExportTestBook(t)
+			var buf bytes.Buffer
+			if err := book.WriteText(&buf, tt.opts); err != nil {
+				t.Fatalf("WriteText() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteText() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	book := openTextExportTestBook(t)
+	var buf bytes.Buffer
+	if err := book.WriteText(&buf, TextExportOptions{}); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("Project Gutenberg License")) {
+		t.Errorf("WriteText() without RemoveLicense dropped the license page:\n%s", buf.String())
+	}
+}
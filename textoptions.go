@@ -0,0 +1,304 @@
+package epub
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/text/unicode/norm"
+)
+
+// RubyMode selects how TextContentWith renders <ruby> annotations.
+type RubyMode int
+
+// Ruby modes.
+const (
+	// RubyKeep keeps base and annotation text as they appear in the
+	// document, as TextContent does.
+	RubyKeep RubyMode = iota
+	// RubyBase keeps only the base text.
+	RubyBase
+	// RubyAnnotation keeps only the annotation (<rt>) text.
+	RubyAnnotation
+	// RubyBoth keeps the base text followed by the annotation in
+	// parentheses, e.g. "漢字(かんじ)". <rp> fallback parentheses are dropped.
+	RubyBoth
+)
+
+// TextOptions configures TextContentWith. The zero value extracts text much
+// like TextContent, from the <body> element.
+type TextOptions struct {
+	// PreserveWhitespace keeps the whitespace of <pre> elements verbatim.
+	PreserveWhitespace bool
+
+	// ImageAlt includes the alt text of images.
+	ImageAlt bool
+
+	// SkipHidden skips elements with the hidden attribute,
+	// aria-hidden="true", or an inline display:none style.
+	SkipHidden bool
+
+	// ListMarkers prefixes list items with "• " (unordered) or "N. "
+	// (ordered), indented by two spaces per nesting level.
+	ListMarkers bool
+
+	// Tables separates table cells with a tab, one row per line.
+	Tables bool
+
+	// RemoveInvisible removes soft hyphens (U+00AD) and zero-width
+	// characters (U+200B, U+2060, U+FEFF). Zero-width joiners are kept, as
+	// some scripts need them.
+	RemoveInvisible bool
+
+	// NFC normalises the text to Unicode Normalization Form C.
+	NFC bool
+
+	// Ruby selects how <ruby> annotations are rendered.
+	Ruby RubyMode
+
+	// Notes selects how notes are handled (see Book.Notes).
+	Notes NoteMode
+}
+
+// invisibleReplacer removes the characters dropped by
+// TextOptions.RemoveInvisible.
+var invisibleReplacer = strings.NewReplacer("\u00ad", "", "\u200b", "", "\u2060", "", "\ufeff", "")
+
+// TextContentWith extracts the plain text content of this chapter's <body>
+// as configured by opts. Block-level elements produce line breaks; script
+// and style content is skipped.
+func (c Chapter) TextContentWith(opts TextOptions) (string, error) {
+	data, err := c.RawContent()
+	if err != nil {
+		return "", err
+	}
+	doc, err := html.Parse(bytes.NewReader(normalizeSelfClosingSkipTags(data)))
+	if err != nil {
+		return "", err
+	}
+
+	var end []string
+	if opts.Notes != NotesKeep {
+		end = applyNoteMode(doc, c.Href, c.book.bookNotes(), opts.Notes)
+	}
+
+	root := findElement(doc, atom.Body)
+	if root == nil {
+		root = doc
+	}
+	w := &textWriter{opts: opts, atLineStart: true}
+	w.walk(root)
+	text := strings.TrimSpace(string(w.buf))
+	if len(end) > 0 {
+		text += "\n\n" + strings.Join(end, "\n\n")
+	}
+
+	if opts.RemoveInvisible {
+		text = invisibleReplacer.Replace(text)
+	}
+	if opts.NFC {
+		text = norm.NFC.String(text)
+	}
+	return text, nil
+}
+
+// textWriter renders a DOM subtree as text for TextContentWith.
+type textWriter struct {
+	opts        TextOptions
+	buf         []byte
+	atLineStart bool
+	atCellStart bool
+	inPre       int
+	listDepth   int
+}
+
+// write appends inline text. Leading spaces are dropped at the start of a
+// line or table cell and after a space.
+func (w *textWriter) write(s string) {
+	if w.inPre == 0 && (w.atLineStart || w.atCellStart || bytes.HasSuffix(w.buf, []byte(" "))) {
+		s = strings.TrimLeft(s, " ")
+	}
+	if s == "" {
+		return
+	}
+	w.buf = append(w.buf, s...)
+	w.atLineStart = strings.HasSuffix(s, "\n")
+	w.atCellStart = false
+}
+
+// newline ends the current line, if it has any content, dropping trailing
+// spaces.
+func (w *textWriter) newline() {
+	if w.atLineStart {
+		return
+	}
+	w.buf = bytes.TrimRight(w.buf, " ")
+	w.buf = append(w.buf, '\n')
+	w.atLineStart = true
+}
+
+// walk renders n and its descendants.
+func (w *textWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if w.inPre > 0 && w.opts.PreserveWhitespace {
+			w.write(n.Data)
+		} else {
+			w.write(collapseWhitespace(n.Data))
+		}
+		return
+	case html.ElementNode:
+	case html.DocumentNode:
+		w.children(n)
+		return
+	default:
+		return
+	}
+
+	if skipTags[n.DataAtom] || (w.opts.SkipHidden && isHiddenElement(n)) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		w.buf = append(w.buf, '\n')
+		w.atLineStart = true
+		return
+	case atom.Img:
+		if w.opts.ImageAlt {
+			if alt := strings.TrimSpace(navGetAttr(n, "alt")); alt != "" {
+				w.write(" " + alt + " ")
+			}
+		}
+		return
+	case atom.Ruby:
+		if w.opts.Ruby != RubyKeep {
+			w.ruby(n)
+			return
+		}
+	case atom.Pre:
+		w.newline()
+		w.inPre++
+		w.children(n)
+		w.inPre--
+		w.newline()
+		return
+	case atom.Ul, atom.Ol:
+		w.newline()
+		w.listDepth++
+		w.listItems(n)
+		w.listDepth--
+		w.newline()
+		return
+	case atom.Td, atom.Th:
+		if w.opts.Tables && prevElementSibling(n) != nil {
+			w.buf = bytes.TrimRight(w.buf, " ")
+			w.buf = append(w.buf, '\t')
+			w.atCellStart = true
+		}
+	}
+
+	if blockTags[n.DataAtom] {
+		w.newline()
+	}
+	w.children(n)
+	if blockTags[n.DataAtom] {
+		w.newline()
+	}
+}
+
+// children renders the children of n.
+func (w *textWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+}
+
+// listItems renders the children of a <ul> or <ol>, prefixing items with
+// markers when ListMarkers is set. Numbering follows parseListItems.
+func (w *textWriter) listItems(list *html.Node) {
+	var items []*html.Node
+	for c := list.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == atom.Li {
+			items = append(items, c)
+		}
+	}
+	step, number := 1, 1
+	if hasAttr(list, "reversed") {
+		step, number = -1, len(items)
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(navGetAttr(list, "start"))); err == nil {
+		number = v
+	}
+
+	for c := list.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li || !w.opts.ListMarkers {
+			w.walk(c)
+			continue
+		}
+		if w.opts.SkipHidden && isHiddenElement(c) {
+			continue
+		}
+		if v, err := strconv.Atoi(strings.TrimSpace(navGetAttr(c, "value"))); err == nil {
+			number = v
+		}
+		marker := "• "
+		if list.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+		}
+		w.newline()
+		w.buf = append(w.buf, strings.Repeat("  ", w.listDepth-1)+marker...)
+		w.atLineStart = true
+		w.children(c)
+		w.newline()
+		number += step
+	}
+}
+
+// ruby renders a <ruby> element according to opts.Ruby.
+func (w *textWriter) ruby(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			switch c.DataAtom {
+			case atom.Rp:
+				continue
+			case atom.Rt:
+				switch w.opts.Ruby {
+				case RubyAnnotation:
+					w.children(c)
+				case RubyBoth:
+					w.write("(")
+					w.children(c)
+					w.write(")")
+				}
+				continue
+			}
+		}
+		if w.opts.Ruby != RubyAnnotation {
+			w.walk(c)
+		}
+	}
+}
+
+// isHiddenElement reports whether n is hidden by the hidden attribute,
+// aria-hidden="true", or an inline display:none style.
+func isHiddenElement(n *html.Node) bool {
+	if hasAttr(n, "hidden") || strings.EqualFold(strings.TrimSpace(navGetAttr(n, "aria-hidden")), "true") {
+		return true
+	}
+	style := strings.ToLower(strings.Join(strings.Fields(navGetAttr(n, "style")), ""))
+	return strings.Contains(style, "display:none")
+}
+
+// prevElementSibling returns the closest preceding sibling element of n, or
+// nil.
+func prevElementSibling(n *html.Node) *html.Node {
+	for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
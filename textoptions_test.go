@@ -0,0 +1,128 @@
+package epub
+
+import (
+	"testing"
+)
+
+func openTextOptionsTestBook(t *testing.T, body string) Chapter {
+	t.Helper()
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:text</dc:identifier>
+    <dc:title>Text</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`,
+		"OEBPS/ch1.xhtml": `<html><head><title>Ignored</title></head><body>` + body + `</body></html>`,
+	})
+	return book.Chapters()[0]
+}
+
+func TestChapter_TextContentWith(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		opts TextOptions
+		want string
+	}{
+		{
+			name: "zero options",
+			body: "<h1>Title</h1>\n<p>Some   <em>text</em>.</p><pre>a\n  b</pre>",
+			want: "Title\nSome text.\na b",
+		},
+		{
+			name: "preserve pre",
+			body: "<p>Code:</p><pre>a\n  b</pre>",
+			opts: TextOptions{PreserveWhitespace: true},
+			want: "Code:\na\n  b",
+		},
+		{
+			name: "image alt",
+			body: `<p>A <img src="x.png" alt="cat"/> sat.</p>`,
+			opts: TextOptions{ImageAlt: true},
+			want: "A cat sat.",
+		},
+		{
+			name: "skip hidden",
+			body: `<p>Shown</p><p hidden="">Hidden</p><p aria-hidden="true">Aria</p><div style="display: none">None</div>`,
+			opts: TextOptions{SkipHidden: true},
+			want: "Shown",
+		},
+		{
+			name: "list markers",
+			body: `<ul><li>One<ol start="3"><li>Three</li><li>Four</li></ol></li><li>Two</li></ul>`,
+			opts: TextOptions{ListMarkers: true},
+			want: "• One\n  3. Three\n  4. Four\n• Two",
+		},
+		{
+			name: "tables",
+			body: `<table><tr><th>Name</th><th> Qty </th></tr><tr><td>Apples</td><td></td></tr></table><p>After</p>`,
+			opts: TextOptions{Tables: true},
+			want: "Name\tQty\nApples\t\nAfter",
+		},
+		{
+			name: "invisible characters",
+			body: "<p>hy\u00adphen\u200bated\ufeff</p>",
+			opts: TextOptions{RemoveInvisible: true},
+			want: "hyphenated",
+		},
+		{
+			name: "NFC",
+			body: "<p>Cafe\u0301</p>",
+			opts: TextOptions{NFC: true},
+			want: "Caf\u00e9",
+		},
+		{
+			name: "ruby keep",
+			body: "<p><ruby>漢<rp>(</rp><rt>かん</rt><rp>)</rp>字<rt>じ</rt></ruby></p>",
+			want: "漢(かん)字じ",
+		},
+		{
+			name: "ruby base",
+			body: "<p><ruby>漢<rp>(</rp><rt>かん</rt><rp>)</rp>字<rt>じ</rt></ruby></p>",
+			opts: TextOptions{Ruby: RubyBase},
+			want: "漢字",
+		},
+		{
+			name: "ruby annotation",
+			body: "<p><ruby>漢<rp>(</rp><rt>かん</rt><rp>)</rp>字<rt>じ</rt></ruby></p>",
+			opts: TextOptions{Ruby: RubyAnnotation},
+			want: "かんじ",
+		},
+		{
+			name: "ruby both",
+			body: "<p><ruby>漢<rp>(</rp><rt>かん</rt><rp>)</rp>字<rt>じ</rt></ruby></p>",
+			opts: TextOptions{Ruby: RubyBoth},
+			want: "漢(かん)字(じ)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := openTextOptionsTestBook(t, tt.body)
+			got, err := ch.TextContentWith(tt.opts)
+			if err != nil {
+				t.Fatalf("TextContentWith() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TextContentWith() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChapter_TextContentWith_Notes(t *testing.T) {
+	book := openNotesTestBook(t)
+	got, err := book.Chapters()[0].TextContentWith(TextOptions{Notes: NotesEnd})
+	if err != nil {
+		t.Fatalf("TextContentWith() error = %v", err)
+	}
+	want := "Text[1] and more[2].\nSee part two.\n\n[1] A footnote.\n\n[2] An endnote."
+	if got != want {
+		t.Errorf("TextContentWith() = %q, want %q", got, want)
+	}
+}
@@ -14,64 +14,52 @@ import (
 // assigns spine indices, and stores results in b.toc and b.landmarks.
 // This is called during initBook after the OPF has been parsed.
 func (b *Book) parseTOC() {
-	// Build a map from file path (without fragment) → spine index.
-	spineMap := make(map[string]int, len(b.spine))
-	for i, si := range b.spine {
-		// Resolve spine item href relative to OPF directory to get ZIP-internal path.
-		href := b.resolveOPFPath(si.Href)
-		spineMap[href] = i
-	}
+	spineMap := b.spineIndexMap()
 
 	isEPub3 := strings.HasPrefix(b.opf.Version, "3")
 
 	spineLen := len(b.spine)
 
+	var navLandmarks []TOCItem
 	if isEPub3 {
 		// ePub 3: prefer nav document, fall back to NCX.
 		if toc, landmarks, ok := b.parseNavTOC(spineMap); ok {
-			b.toc = toc
-			b.landmarks = landmarks
-			computeSpineRanges(b.toc, spineLen)
-			return
+			if len(toc) > 0 {
+				b.toc = toc
+				b.landmarks = landmarks
+				computeSpineRanges(b.toc, spineLen)
+				return
+			}
+			// The nav document has no usable toc nav; keep its landmarks
+			// and try the NCX instead.
+			navLandmarks = landmarks
+			if b.ncxPath() != "" {
+				b.warnings = append(b.warnings, "nav document has an empty toc; using NCX")
+			}
 		}
 	}
 
 	// ePub 2 or ePub 3 without nav document: use NCX.
 	if toc, ok := b.parseNCXTOC(spineMap); ok {
 		b.toc = toc
+		b.landmarks = navLandmarks
 		computeSpineRanges(b.toc, spineLen)
 		return
 	}
 
 	// No TOC found — expose empty TOC/landmarks slices to callers.
 	b.toc = []TOCItem{}
-	b.landmarks = nil
+	b.landmarks = navLandmarks
 }
 
 // parseNavTOC finds and parses the nav document, assigns spine indices,
 // and returns (toc, landmarks, true). Returns (nil, nil, false) if no nav document is found.
 func (b *Book) parseNavTOC(spineMap map[string]int) ([]TOCItem, []TOCItem, bool) {
-	// Find the manifest item with properties containing "nav".
-	// Iterate the OPF slice (not the map) to get deterministic document order.
-	var navItem *manifestItem
-	for _, raw := range b.opf.Manifest.Items {
-		for _, prop := range strings.Fields(raw.Properties) {
-			if prop == "nav" {
-				navItem = b.manifestByID[raw.ID]
-				break
-			}
-		}
-		if navItem != nil {
-			break
-		}
-	}
-	if navItem == nil {
+	navPath := b.navDocumentPath()
+	if navPath == "" {
 		return nil, nil, false
 	}
 
-	// Resolve nav document path relative to OPF directory.
-	navPath := b.resolveOPFPath(navItem.Href)
-
 	f := b.findFile(navPath)
 	if f == nil {
 		return nil, nil, false
@@ -82,6 +70,7 @@ func (b *Book) parseNavTOC(spineMap map[string]int) ([]TOCItem, []TOCItem, bool)
 		b.warnings = append(b.warnings, fmt.Sprintf("failed to read nav document: %v", err))
 		return nil, nil, false
 	}
+	data = b.transcode(navPath, data)
 
 	toc, landmarks, err := parseNavDocument(data, navPath)
 	if err != nil {
@@ -98,19 +87,11 @@ func (b *Book) parseNavTOC(spineMap map[string]int) ([]TOCItem, []TOCItem, bool)
 // parseNCXTOC finds and parses the NCX file, assigns spine indices,
 // and returns (toc, true). Returns (nil, false) if no NCX is found.
 func (b *Book) parseNCXTOC(spineMap map[string]int) ([]TOCItem, bool) {
-	tocID := b.opf.Spine.Toc
-	if tocID == "" {
+	ncxPath := b.ncxPath()
+	if ncxPath == "" {
 		return nil, false
 	}
 
-	ncxItem, ok := b.manifestByID[tocID]
-	if !ok {
-		return nil, false
-	}
-
-	// Resolve NCX path relative to OPF directory.
-	ncxPath := b.resolveOPFPath(ncxItem.Href)
-
 	f := b.findFile(ncxPath)
 	if f == nil {
 		return nil, false
@@ -121,6 +102,7 @@ func (b *Book) parseNCXTOC(spineMap map[string]int) ([]TOCItem, bool) {
 		b.warnings = append(b.warnings, fmt.Sprintf("failed to read NCX file: %v", err))
 		return nil, false
 	}
+	data = b.transcode(ncxPath, data)
 
 	toc, err := parseNCX(data, ncxPath)
 	if err != nil {
@@ -133,6 +115,49 @@ func (b *Book) parseNCXTOC(spineMap map[string]int) ([]TOCItem, bool) {
 	return toc, true
 }
 
+// spineIndexMap builds a map from ZIP-internal file path (without fragment)
+// to spine index.
+func (b *Book) spineIndexMap() map[string]int {
+	spineMap := make(map[string]int, len(b.spine))
+	for i, si := range b.spine {
+		// Resolve spine item href relative to OPF directory to get ZIP-internal path.
+		href := b.resolveOPFPath(si.Href)
+		spineMap[href] = i
+	}
+	return spineMap
+}
+
+// navDocumentPath returns the ZIP-internal path of the ePub 3 nav document,
+// i.e. the first manifest item whose properties contain "nav".
+// Returns "" if the manifest declares no nav document.
+func (b *Book) navDocumentPath() string {
+	// Iterate the OPF slice (not the map) to get deterministic document order.
+	for _, raw := range b.opf.Manifest.Items {
+		for _, prop := range strings.Fields(raw.Properties) {
+			if prop == "nav" {
+				if item, ok := b.manifestByID[raw.ID]; ok {
+					return b.resolveOPFPath(item.Href)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// ncxPath returns the ZIP-internal path of the NCX referenced by the spine's
+// toc attribute, or "" if there is none.
+func (b *Book) ncxPath() string {
+	tocID := b.opf.Spine.Toc
+	if tocID == "" {
+		return ""
+	}
+	ncxItem, ok := b.manifestByID[tocID]
+	if !ok {
+		return ""
+	}
+	return b.resolveOPFPath(ncxItem.Href)
+}
+
 // assignSpineIndices recursively sets SpineIndex on each TOCItem by matching
 // its Href (without fragment) against the spine map.
 func assignSpineIndices(items []TOCItem, spineMap map[string]int) {
@@ -221,8 +246,22 @@ func flattenTOCItems(flat *[]*TOCItem, items []TOCItem) {
 
 // ncxDocument represents the root <ncx> element of an NCX file.
 type ncxDocument struct {
-	XMLName xml.Name  `xml:"ncx"`
-	NavMap  ncxNavMap `xml:"navMap"`
+	XMLName  xml.Name     `xml:"ncx"`
+	Head     ncxHead      `xml:"head"`
+	NavMap   ncxNavMap    `xml:"navMap"`
+	PageList ncxPageList  `xml:"pageList"`
+	NavLists []ncxNavList `xml:"navList"`
+}
+
+// ncxHead represents the <head> element holding the dtb:* metadata.
+type ncxHead struct {
+	Metas []ncxMeta `xml:"meta"`
+}
+
+// ncxMeta represents a <meta name="..." content="..."> element in the NCX head.
+type ncxMeta struct {
+	Name    string `xml:"name,attr"`
+	Content string `xml:"content,attr"`
 }
 
 // ncxNavMap represents the <navMap> element containing top-level navPoints.
@@ -239,6 +278,38 @@ type ncxNavPoint struct {
 	Children  []ncxNavPoint `xml:"navPoint"`
 }
 
+// ncxPageList represents the <pageList> element containing pageTargets.
+type ncxPageList struct {
+	Targets []ncxPageTarget `xml:"pageTarget"`
+}
+
+// ncxPageTarget represents a <pageTarget> element mapping a print page to content.
+type ncxPageTarget struct {
+	ID        string      `xml:"id,attr"`
+	Type      string      `xml:"type,attr"`
+	Value     string      `xml:"value,attr"`
+	PlayOrder string      `xml:"playOrder,attr"`
+	Label     ncxNavLabel `xml:"navLabel"`
+	Content   ncxContent  `xml:"content"`
+}
+
+// ncxNavList represents a <navList> element, a flat list of navTargets such
+// as a list of illustrations.
+type ncxNavList struct {
+	Class   string         `xml:"class,attr"`
+	Label   ncxNavLabel    `xml:"navLabel"`
+	Targets []ncxNavTarget `xml:"navTarget"`
+}
+
+// ncxNavTarget represents a <navTarget> element inside a navList.
+type ncxNavTarget struct {
+	ID        string      `xml:"id,attr"`
+	Class     string      `xml:"class,attr"`
+	PlayOrder string      `xml:"playOrder,attr"`
+	Label     ncxNavLabel `xml:"navLabel"`
+	Content   ncxContent  `xml:"content"`
+}
+
 // ncxNavLabel represents the <navLabel> element containing the display text.
 type ncxNavLabel struct {
 	Text string `xml:"text"`
@@ -253,18 +324,24 @@ type ncxContent struct {
 // ncxPath is the ZIP-internal path to the NCX file (e.g., "OEBPS/toc.ncx"),
 // used to resolve relative hrefs to ZIP root-relative paths.
 func parseNCX(data []byte, ncxPath string) ([]TOCItem, error) {
-	data = preprocessHTMLEntities(data)
-	data = stripBOM(data)
-
-	var doc ncxDocument
-	if err := xml.Unmarshal(data, &doc); err != nil {
-		return nil, fmt.Errorf("epub: parse NCX: %w", err)
+	doc, err := decodeNCX(data)
+	if err != nil {
+		return nil, err
 	}
 
 	items := convertNavPoints(doc.NavMap.NavPoints, ncxPath)
 	return items, nil
 }
 
+// decodeNCX unmarshals NCX data leniently (see decodeXML).
+func decodeNCX(data []byte) (*ncxDocument, error) {
+	var doc ncxDocument
+	if err := decodeXML(data, &doc); err != nil {
+		return nil, fmt.Errorf("epub: parse NCX: %w", err)
+	}
+	return &doc, nil
+}
+
 // convertNavPoints recursively converts ncxNavPoint elements into TOCItem entries.
 func convertNavPoints(points []ncxNavPoint, ncxPath string) []TOCItem {
 	if len(points) == 0 {
@@ -366,6 +443,7 @@ func parseNavLI(li *html.Node, basePath string) TOCItem {
 					}
 				}
 				item.Title = strings.TrimSpace(nodeTextContent(c))
+				item.Type = strings.Join(strings.Fields(navGetAttr(c, "epub:type")), " ")
 			}
 		case "span":
 			// Use <span> text only if no <a> has been found yet.
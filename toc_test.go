@@ -510,6 +510,12 @@ func TestParseNavDocument_WithLandmarks(t *testing.T) {
 	if landmarks[1].Href != "OEBPS/chapter1.xhtml" {
 		t.Errorf("landmarks[1].Href = %q, want %q", landmarks[1].Href, "OEBPS/chapter1.xhtml")
 	}
+	if landmarks[0].Type != "toc" || landmarks[1].Type != "bodymatter" {
+		t.Errorf("landmark types = %q, %q, want %q, %q", landmarks[0].Type, landmarks[1].Type, "toc", "bodymatter")
+	}
+	if toc[0].Type != "" {
+		t.Errorf("toc[0].Type = %q, want empty", toc[0].Type)
+	}
 }
 
 func TestParseNavDocument_SpanTitles(t *testing.T) {
@@ -0,0 +1,358 @@
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// TOC sources reported in TOCIssue.Source.
+const (
+	TOCSourceNav = "nav"
+	TOCSourceNCX = "ncx"
+)
+
+// TOCIssue is a single finding of CheckTOC.
+type TOCIssue struct {
+	// Rule is a short machine-readable rule name (e.g., "dead-link").
+	Rule string
+
+	// Severity grades the finding.
+	Severity IssueSeverity
+
+	// Source is the TOC the finding relates to (TOCSourceNav or
+	// TOCSourceNCX), or empty for findings about both.
+	Source string
+
+	// Href is the TOC entry target the finding relates to, if any.
+	Href string
+
+	// Message is a human-readable description of the finding.
+	Message string
+}
+
+// TOCReport is the result of cross-checking the nav document TOC against
+// the NCX.
+type TOCReport struct {
+	// Nav is the toc of the ePub 3 nav document, or nil if there is none.
+	Nav []TOCItem
+
+	// NCX is the navMap of the NCX, or nil if there is none.
+	NCX []TOCItem
+
+	// Merged is the best TOC assembled from both sources: the source with
+	// more working links, with dead links and missing titles repaired from
+	// the other source where a matching entry exists.
+	Merged []TOCItem
+
+	// Issues lists every finding in report order.
+	Issues []TOCIssue
+}
+
+// Passed reports whether the report contains no error-severity issues.
+func (r TOCReport) Passed() bool {
+	for _, is := range r.Issues {
+		if is.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// add appends an issue to the report.
+func (r *TOCReport) add(rule string, severity IssueSeverity, source, href, msg string) {
+	r.Issues = append(r.Issues, TOCIssue{
+		Rule:     rule,
+		Severity: severity,
+		Source:   source,
+		Href:     href,
+		Message:  msg,
+	})
+}
+
+// CheckTOC parses both the nav document and the NCX (when present) and
+// compares them: entries present in only one of them, entries whose titles
+// differ, links to missing files or fragments, and spine indices that go
+// backwards in reading order. The NCX dtb:uid is checked against the package
+// unique identifier. The report also carries a merged TOC built from both.
+//
+// CheckTOC does not change the TOC returned by TOC.
+func (b *Book) CheckTOC() TOCReport {
+	var r TOCReport
+	spineMap := b.spineIndexMap()
+
+	if navPath := b.navDocumentPath(); navPath != "" {
+		data, err := b.readContent(navPath)
+		if err != nil {
+			r.add("unreadable", SeverityError, TOCSourceNav, navPath, fmt.Sprintf("cannot read nav document: %v", err))
+		} else if toc, _, err := parseNavDocument(data, navPath); err != nil {
+			r.add("unparsable", SeverityError, TOCSourceNav, navPath, fmt.Sprintf("cannot parse nav document: %v", err))
+		} else {
+			assignSpineIndices(toc, spineMap)
+			computeSpineRanges(toc, len(b.spine))
+			r.Nav = toc
+			if len(toc) == 0 {
+				r.add("empty", SeverityError, TOCSourceNav, navPath, "nav document has no toc entries")
+			}
+		}
+	}
+
+	if ncxPath := b.ncxPath(); ncxPath != "" {
+		data, err := b.readContent(ncxPath)
+		if err != nil {
+			r.add("unreadable", SeverityError, TOCSourceNCX, ncxPath, fmt.Sprintf("cannot read NCX: %v", err))
+		} else if doc, err := decodeNCX(data); err != nil {
+			r.add("unparsable", SeverityError, TOCSourceNCX, ncxPath, fmt.Sprintf("cannot parse NCX: %v", err))
+		} else {
+			toc := convertNavPoints(doc.NavMap.NavPoints, ncxPath)
+			assignSpineIndices(toc, spineMap)
+			computeSpineRanges(toc, len(b.spine))
+			r.NCX = toc
+			if len(toc) == 0 {
+				r.add("empty", SeverityError, TOCSourceNCX, ncxPath, "NCX navMap has no entries")
+			}
+			b.checkNCXUID(&r, doc)
+		}
+	}
+
+	ids := make(map[string]map[string]bool)
+	navDead := b.checkTOCLinks(&r, TOCSourceNav, r.Nav, ids)
+	ncxDead := b.checkTOCLinks(&r, TOCSourceNCX, r.NCX, ids)
+	checkTOCOrder(&r, TOCSourceNav, r.Nav)
+	checkTOCOrder(&r, TOCSourceNCX, r.NCX)
+	if r.Nav != nil && r.NCX != nil {
+		compareTOCs(&r)
+	}
+
+	r.Merged = mergeTOCs(r.Nav, r.NCX, navDead, ncxDead)
+	computeSpineRanges(r.Merged, len(b.spine))
+	return r
+}
+
+// checkNCXUID compares the NCX dtb:uid with the package unique identifier.
+func (b *Book) checkNCXUID(r *TOCReport, doc *ncxDocument) {
+	uid, found := "", false
+	for _, m := range doc.Head.Metas {
+		if m.Name == "dtb:uid" {
+			uid, found = strings.TrimSpace(m.Content), true
+			break
+		}
+	}
+	if !found {
+		r.add("dtb-uid", SeverityWarning, TOCSourceNCX, "", "NCX head has no dtb:uid")
+		return
+	}
+
+	pkgID := b.packageIdentifier()
+	if pkgID == "" {
+		return
+	}
+	if uid != pkgID {
+		r.add("dtb-uid", SeverityError, TOCSourceNCX, "",
+			fmt.Sprintf("NCX dtb:uid %q does not match package identifier %q", uid, pkgID))
+	}
+}
+
+// packageIdentifier returns the dc:identifier referenced by the package
+// unique-identifier attribute, falling back to the first identifier.
+func (b *Book) packageIdentifier() string {
+	for _, id := range b.metadata.Identifiers {
+		if id.ID != "" && id.ID == b.opf.UniqueIdentifier {
+			return id.Value
+		}
+	}
+	if len(b.metadata.Identifiers) > 0 {
+		return b.metadata.Identifiers[0].Value
+	}
+	return ""
+}
+
+// checkTOCLinks reports entries that link to a file missing from the
+// archive or to a fragment id absent from the target document. It returns
+// the set of dead hrefs. ids caches the ids of each target document.
+func (b *Book) checkTOCLinks(r *TOCReport, source string, toc []TOCItem, ids map[string]map[string]bool) map[string]bool {
+	dead := make(map[string]bool)
+	var flat []*TOCItem
+	flattenTOCItems(&flat, toc)
+	for _, item := range flat {
+		if item.Href == "" || dead[item.Href] {
+			continue
+		}
+		file, frag, _ := strings.Cut(item.Href, "#")
+		if b.findFile(file) == nil {
+			dead[item.Href] = true
+			r.add("dead-link", SeverityError, source, item.Href,
+				fmt.Sprintf("entry %q links to a file that is not in the archive", item.Title))
+			continue
+		}
+		if frag == "" {
+			continue
+		}
+		docIDs, ok := ids[file]
+		if !ok {
+			data, _ := b.readContent(file)
+			docIDs = documentIDs(data)
+			ids[file] = docIDs
+		}
+		if !docIDs[frag] {
+			dead[item.Href] = true
+			r.add("dead-fragment", SeverityWarning, source, item.Href,
+				fmt.Sprintf("entry %q links to fragment #%s, which is not defined", item.Title, frag))
+		}
+	}
+	return dead
+}
+
+// documentIDs returns the set of id attributes (and <a name> anchors) in an
+// XHTML document.
+func documentIDs(data []byte) map[string]bool {
+	ids := make(map[string]bool)
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ids
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tn, hasAttr := tokenizer.TagName()
+			isAnchor := string(tn) == "a"
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = tokenizer.TagAttr()
+				if string(key) == "id" || string(key) == "xml:id" || (isAnchor && string(key) == "name") {
+					ids[string(val)] = true
+				}
+			}
+		}
+	}
+}
+
+// checkTOCOrder reports entries whose spine index is lower than that of a
+// preceding entry in reading order.
+func checkTOCOrder(r *TOCReport, source string, toc []TOCItem) {
+	var flat []*TOCItem
+	flattenTOCItems(&flat, toc)
+	last := -1
+	for _, item := range flat {
+		if item.SpineIndex < 0 {
+			continue
+		}
+		if item.SpineIndex < last {
+			r.add("spine-order", SeverityWarning, source, item.Href,
+				fmt.Sprintf("entry %q points to spine item %d after an entry pointing to spine item %d", item.Title, item.SpineIndex, last))
+		}
+		last = item.SpineIndex
+	}
+}
+
+// compareTOCs reports entries present in only one of the nav and NCX TOCs,
+// and entries with the same target but different titles.
+func compareTOCs(r *TOCReport) {
+	navByHref := tocEntriesByHref(r.Nav)
+	ncxByHref := tocEntriesByHref(r.NCX)
+
+	var flat []*TOCItem
+	flattenTOCItems(&flat, r.Nav)
+	for _, item := range flat {
+		if item.Href == "" {
+			continue
+		}
+		other, ok := ncxByHref[item.Href]
+		if !ok {
+			r.add("nav-only", SeverityWarning, TOCSourceNav, item.Href,
+				fmt.Sprintf("entry %q is missing from the NCX", item.Title))
+			continue
+		}
+		if !sameTOCTitle(item.Title, other.Title) {
+			r.add("title-mismatch", SeverityWarning, "", item.Href,
+				fmt.Sprintf("nav title %q differs from NCX title %q", item.Title, other.Title))
+		}
+	}
+
+	flat = flat[:0]
+	flattenTOCItems(&flat, r.NCX)
+	for _, item := range flat {
+		if item.Href == "" {
+			continue
+		}
+		if _, ok := navByHref[item.Href]; !ok {
+			r.add("ncx-only", SeverityWarning, TOCSourceNCX, item.Href,
+				fmt.Sprintf("entry %q is missing from the nav document", item.Title))
+		}
+	}
+}
+
+// tocEntriesByHref indexes the entries of a TOC tree by href; the first
+// entry wins.
+func tocEntriesByHref(toc []TOCItem) map[string]*TOCItem {
+	var flat []*TOCItem
+	flattenTOCItems(&flat, toc)
+	m := make(map[string]*TOCItem, len(flat))
+	for _, item := range flat {
+		if _, exists := m[item.Href]; !exists && item.Href != "" {
+			m[item.Href] = item
+		}
+	}
+	return m
+}
+
+// sameTOCTitle reports whether two titles are equal ignoring case and
+// whitespace differences.
+func sameTOCTitle(a, b string) bool {
+	return strings.EqualFold(collapseWhitespace(strings.TrimSpace(a)), collapseWhitespace(strings.TrimSpace(b)))
+}
+
+// mergeTOCs picks the TOC with more working links as the base (the nav on a
+// tie) and repairs it from the other one: empty titles are filled from the
+// entry with the same href, and dead links are replaced by the live href of
+// an entry with the same title.
+func mergeTOCs(nav, ncx []TOCItem, navDead, ncxDead map[string]bool) []TOCItem {
+	base, baseDead, other, otherDead := nav, navDead, ncx, ncxDead
+	if liveTOCLinks(ncx, ncxDead) > liveTOCLinks(nav, navDead) {
+		base, baseDead, other, otherDead = ncx, ncxDead, nav, navDead
+	}
+
+	merged := copyTOCItems(base)
+	if len(merged) == 0 {
+		return []TOCItem{}
+	}
+
+	otherByHref := tocEntriesByHref(other)
+	var otherFlat []*TOCItem
+	flattenTOCItems(&otherFlat, other)
+
+	var flat []*TOCItem
+	flattenTOCItems(&flat, merged)
+	for _, item := range flat {
+		if item.Title == "" {
+			if o, ok := otherByHref[item.Href]; ok {
+				item.Title = o.Title
+			}
+		}
+		if item.Href == "" || !baseDead[item.Href] {
+			continue
+		}
+		for _, o := range otherFlat {
+			if o.Href != "" && !otherDead[o.Href] && sameTOCTitle(o.Title, item.Title) {
+				item.Href = o.Href
+				item.SpineIndex = o.SpineIndex
+				break
+			}
+		}
+	}
+	return merged
+}
+
+// liveTOCLinks counts the entries of toc with a non-dead href.
+func liveTOCLinks(toc []TOCItem, dead map[string]bool) int {
+	var flat []*TOCItem
+	flattenTOCItems(&flat, toc)
+	n := 0
+	for _, item := range flat {
+		if item.Href != "" && !dead[item.Href] {
+			n++
+		}
+	}
+	return n
+}
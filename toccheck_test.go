@@ -0,0 +1,184 @@
+package epub
+
+import (
+	"reflect"
+	"testing"
+)
+
+const tocCheckTestOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="isbn">9780000000000</dc:identifier>
+    <dc:identifier id="uid">urn:uuid:toccheck</dc:identifier>
+    <dc:title>TOC Check</dc:title>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch3" href="ch3.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+    <itemref idref="ch3"/>
+  </spine>
+</package>`
+
+func tocCheckTestFiles(nav, ncx string) map[string]string {
+	return map[string]string{
+		"OEBPS/content.opf": tocCheckTestOPF,
+		"OEBPS/nav.xhtml":   nav,
+		"OEBPS/toc.ncx":     ncx,
+		"OEBPS/ch1.xhtml":   `<html><body><h1 id="top">One</h1></body></html>`,
+		"OEBPS/ch2.xhtml":   `<html><body><h1>Two</h1><a name="s2"></a></body></html>`,
+		"OEBPS/ch3.xhtml":   `<html><body><h1>Three</h1></body></html>`,
+	}
+}
+
+func TestBook_CheckTOC(t *testing.T) {
+	nav := `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><nav epub:type="toc"><ol>
+<li><a href="ch1.xhtml#top">One</a></li>
+<li><a href="ch3.xhtml">Three</a></li>
+<li><a href="ch2.xhtml#s2">Two  B</a></li>
+<li><a href="gone.xhtml">Four</a></li>
+</ol></nav></body></html>`
+	ncx := `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head><meta name="dtb:uid" content="urn:uuid:other"/></head>
+  <navMap>
+    <navPoint id="n1" playOrder="1"><navLabel><text>one</text></navLabel><content src="ch1.xhtml#top"/></navPoint>
+    <navPoint id="n2" playOrder="2"><navLabel><text>Two</text></navLabel><content src="ch2.xhtml#s2"/></navPoint>
+    <navPoint id="n3" playOrder="3"><navLabel><text>Four</text></navLabel><content src="ch3.xhtml#missing"/></navPoint>
+    <navPoint id="n4" playOrder="4"><navLabel><text>Extra</text></navLabel><content src="ch3.xhtml"/></navPoint>
+  </navMap>
+</ncx>`
+	book := openPageListTestBook(t, tocCheckTestFiles(nav, ncx))
+
+	r := book.CheckTOC()
+	if len(r.Nav) != 4 || len(r.NCX) != 4 {
+		t.Fatalf("Nav/NCX entries = %d/%d, want 4/4", len(r.Nav), len(r.NCX))
+	}
+
+	type finding struct{ rule, source, href string }
+	var got []finding
+	for _, is := range r.Issues {
+		got = append(got, finding{is.Rule, is.Source, is.Href})
+	}
+	want := []finding{
+		{"dtb-uid", TOCSourceNCX, ""},
+		{"dead-link", TOCSourceNav, "OEBPS/gone.xhtml"},
+		{"dead-fragment", TOCSourceNCX, "OEBPS/ch3.xhtml#missing"},
+		{"spine-order", TOCSourceNav, "OEBPS/ch2.xhtml#s2"},
+		{"title-mismatch", "", "OEBPS/ch3.xhtml"},
+		{"title-mismatch", "", "OEBPS/ch2.xhtml#s2"},
+		{"nav-only", TOCSourceNav, "OEBPS/gone.xhtml"},
+		{"ncx-only", TOCSourceNCX, "OEBPS/ch3.xhtml#missing"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Issues = %+v, want %+v", got, want)
+	}
+	if r.Passed() {
+		t.Error("Passed() = true, want false")
+	}
+
+	// Both sources have three working links; the nav wins the tie and its
+	// dead "Four" entry cannot be repaired because the NCX "Four" is dead too.
+	var titles, hrefs []string
+	for _, item := range r.Merged {
+		titles = append(titles, item.Title)
+		hrefs = append(hrefs, item.Href)
+	}
+	if !reflect.DeepEqual(titles, []string{"One", "Three", "Two  B", "Four"}) {
+		t.Errorf("Merged titles = %v", titles)
+	}
+	if hrefs[3] != "OEBPS/gone.xhtml" {
+		t.Errorf("Merged[3].Href = %q, want unrepaired dead link", hrefs[3])
+	}
+
+	// The book TOC itself is unchanged.
+	if toc := book.TOC(); len(toc) != 4 || toc[3].Href != "OEBPS/gone.xhtml" {
+		t.Errorf("TOC() = %+v, want the nav TOC", toc)
+	}
+}
+
+func TestBook_CheckTOC_MergeRepairs(t *testing.T) {
+	nav := `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><nav epub:type="toc"><ol>
+<li><a href="ch1.xhtml">One</a></li>
+<li><a href="ch2.xhtml"></a></li>
+<li><a href="oops.xhtml">Three</a></li>
+</ol></nav></body></html>`
+	ncx := `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head><meta name="dtb:uid" content="urn:uuid:toccheck"/></head>
+  <navMap>
+    <navPoint id="n1" playOrder="1"><navLabel><text>One</text></navLabel><content src="ch1.xhtml"/></navPoint>
+    <navPoint id="n2" playOrder="2"><navLabel><text>Two</text></navLabel><content src="ch2.xhtml"/></navPoint>
+    <navPoint id="n3" playOrder="3"><navLabel><text>Three</text></navLabel><content src="ch3.xhtml"/></navPoint>
+  </navMap>
+</ncx>`
+	book := openPageListTestBook(t, tocCheckTestFiles(nav, ncx))
+
+	r := book.CheckTOC()
+	for _, is := range r.Issues {
+		if is.Rule == "dtb-uid" {
+			t.Errorf("unexpected dtb-uid issue: %s", is.Message)
+		}
+	}
+
+	// The NCX has more working links, so it becomes the base.
+	want := []TOCItem{
+		{Title: "One", Href: "OEBPS/ch1.xhtml", SpineIndex: 0, SpineEndIndex: 1},
+		{Title: "Two", Href: "OEBPS/ch2.xhtml", SpineIndex: 1, SpineEndIndex: 2},
+		{Title: "Three", Href: "OEBPS/ch3.xhtml", SpineIndex: 2, SpineEndIndex: 3},
+	}
+	if !reflect.DeepEqual(r.Merged, want) {
+		t.Errorf("Merged = %+v, want %+v", r.Merged, want)
+	}
+}
+
+func TestMergeTOCs_RepairsBase(t *testing.T) {
+	nav := []TOCItem{
+		{Title: "", Href: "a.xhtml", SpineIndex: 0},
+		{Title: "B", Href: "dead.xhtml", SpineIndex: -1},
+	}
+	ncx := []TOCItem{
+		{Title: "A", Href: "a.xhtml", SpineIndex: 0},
+		{Title: "b", Href: "b.xhtml", SpineIndex: 1},
+	}
+	// One live link each, so the nav is the base.
+	got := mergeTOCs(nav, ncx, map[string]bool{"dead.xhtml": true}, map[string]bool{"a.xhtml": true})
+	want := []TOCItem{
+		{Title: "A", Href: "a.xhtml", SpineIndex: 0},
+		{Title: "B", Href: "b.xhtml", SpineIndex: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeTOCs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTOC_EmptyNavFallsBackToNCX(t *testing.T) {
+	nav := `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<nav epub:type="toc"><ol></ol></nav>
+<nav epub:type="landmarks"><ol><li><a epub:type="bodymatter" href="ch1.xhtml">Start</a></li></ol></nav>
+</body></html>`
+	ncx := `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="n1" playOrder="1"><navLabel><text>One</text></navLabel><content src="ch1.xhtml"/></navPoint>
+  </navMap>
+</ncx>`
+	book := openPageListTestBook(t, tocCheckTestFiles(nav, ncx))
+
+	toc := book.TOC()
+	if len(toc) != 1 || toc[0].Title != "One" {
+		t.Errorf("TOC() = %+v, want the NCX entry", toc)
+	}
+	if lm := book.Landmarks(); len(lm) != 1 || lm[0].Title != "Start" {
+		t.Errorf("Landmarks() = %+v, want the nav landmarks", lm)
+	}
+	if !containsWarning(book.Warnings(), "empty toc") {
+		t.Errorf("Warnings() = %v, want empty toc warning", book.Warnings())
+	}
+}
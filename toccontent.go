@@ -0,0 +1,230 @@
+package epub
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ContentFormat selects the output format of content extraction methods.
+type ContentFormat int
+
+// Content formats.
+const (
+	// ContentText produces plain text, as Chapter.TextContent does.
+	ContentText ContentFormat = iota
+	// ContentHTML produces sanitised HTML, as Chapter.BodyHTML does.
+	ContentHTML
+)
+
+// TOCContent returns the content of a single TOC entry: everything from the
+// element its href points to up to, but not including, the element the next
+// TOC entry (in reading order) points to. The range may span several spine
+// documents; the parts are joined with a blank line (text) or a newline
+// (HTML).
+//
+// The "next" entry is the following entry of the flattened TOC, so the
+// content of an entry with children ends where its first child begins. If
+// item is not part of the TOC, the content runs to item.SpineEndIndex.
+// A missing start fragment id is treated as the start of its document. A
+// missing end fragment id is treated as the end of the document when the
+// range starts in that document, and as its start otherwise.
+//
+// Returns ErrNotInSpine if item does not point to a spine document.
+func (b *Book) TOCContent(item TOCItem, format ContentFormat) (string, error) {
+	spineMap := b.spineIndexMap()
+	startFile, startFrag, _ := strings.Cut(item.Href, "#")
+	startIdx, ok := spineMap[startFile]
+	if !ok {
+		return "", ErrNotInSpine
+	}
+
+	endIdx, endFrag := b.tocContentEnd(item, startIdx, spineMap)
+
+	var parts []string
+	for i := startIdx; i < len(b.spine) && i <= endIdx; i++ {
+		href := b.resolveOPFPath(b.spine[i].Href)
+		var from, to string
+		if i == startIdx {
+			from = startFrag
+		}
+		if i == endIdx {
+			if endFrag == "" {
+				break // the next entry starts at the top of this document
+			}
+			to = endFrag
+		}
+		part, err := b.documentRange(href, from, to, i != startIdx, format)
+		if err != nil {
+			return "", err
+		}
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	sep := "\n\n"
+	if format == ContentHTML {
+		sep = "\n"
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// tocContentEnd locates where the content of item ends: the spine index and
+// fragment of the next TOC entry. A fragment of "" means the content ends at
+// the start of that spine document. When there is no next entry the end
+// index is len(b.spine).
+func (b *Book) tocContentEnd(item TOCItem, startIdx int, spineMap map[string]int) (int, string) {
+	var flat []*TOCItem
+	flattenTOCItems(&flat, b.toc)
+
+	pos := -1
+	for i, e := range flat {
+		if e.Href == item.Href && e.Title == item.Title {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 {
+		end := item.SpineEndIndex
+		if end <= startIdx {
+			end = startIdx + 1
+		}
+		return end, ""
+	}
+
+	for _, e := range flat[pos+1:] {
+		if e.Href == "" || e.Href == item.Href {
+			continue
+		}
+		file, frag, _ := strings.Cut(e.Href, "#")
+		idx, ok := spineMap[file]
+		if !ok || idx < startIdx || (idx == startIdx && frag == "") {
+			continue
+		}
+		return idx, frag
+	}
+	return len(b.spine), ""
+}
+
+// documentRange returns the content of the document at href between the
+// elements with ids from and to. An empty from starts at the beginning of
+// the body; an empty to runs to its end. A to id that is not found runs to
+// the end of the body too, unless stopAtMissing is set, in which case the
+// range is empty.
+func (b *Book) documentRange(href, from, to string, stopAtMissing bool, format ContentFormat) (string, error) {
+	data, err := b.readFile(href)
+	if err != nil {
+		return "", err
+	}
+	data = normalizeSelfClosingSkipTags(stripBOM(data))
+
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	body := findElement(doc, atom.Body)
+	if body == nil {
+		return "", nil
+	}
+
+	var start, end *html.Node
+	if from != "" {
+		start = findElementByID(body, from)
+	}
+	if to != "" {
+		end = findElementByID(body, to)
+		if end == nil && stopAtMissing {
+			return "", nil
+		}
+	}
+
+	phase := rangeInside
+	if start != nil {
+		phase = rangeBefore
+	}
+	clone := cloneRange(body, start, end, &phase)
+	if clone == nil {
+		return "", nil
+	}
+
+	if format == ContentHTML {
+		rewriteImageNode(clone, href)
+		cleanNode(clone)
+		var buf bytes.Buffer
+		for c := clone.FirstChild; c != nil; c = c.NextSibling {
+			if err := html.Render(&buf, c); err != nil {
+				return "", err
+			}
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, clone); err != nil {
+		return "", err
+	}
+	return extractText(buf.Bytes())
+}
+
+// Range extraction phases for cloneRange.
+const (
+	rangeBefore = iota // before the start node
+	rangeInside        // between start and end
+	rangeAfter         // at or after the end node
+)
+
+// cloneRange returns a copy of the subtree rooted at n that keeps only the
+// nodes from start (inclusive) to end (exclusive) in document order, plus
+// the ancestors needed to hold them. A nil start or end means the range is
+// open on that side. phase carries the walk state between calls; an end
+// node met before start is ignored. Returns nil if nothing in n is kept.
+func cloneRange(n, start, end *html.Node, phase *int) *html.Node {
+	if n == end && *phase == rangeInside {
+		*phase = rangeAfter
+	}
+	if n == start && *phase == rangeBefore {
+		*phase = rangeInside
+	}
+	if *phase == rangeAfter {
+		return nil
+	}
+
+	inside := *phase == rangeInside
+	c := &html.Node{
+		Type:      n.Type,
+		DataAtom:  n.DataAtom,
+		Data:      n.Data,
+		Namespace: n.Namespace,
+		Attr:      append([]html.Attribute(nil), n.Attr...),
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if cc := cloneRange(child, start, end, phase); cc != nil {
+			c.AppendChild(cc)
+		}
+	}
+	// Drop wrappers that hold nothing from the range: ancestors of start
+	// preceding it, and ancestors of end whose content all follows it.
+	if (!inside || *phase == rangeAfter) && c.FirstChild == nil {
+		return nil
+	}
+	return c
+}
+
+// findElementByID returns the first element in the subtree rooted at n whose
+// id (or, for <a>, name) attribute equals id.
+func findElementByID(n *html.Node, id string) *html.Node {
+	if n.Type == html.ElementNode {
+		if navGetAttr(n, "id") == id || (n.DataAtom == atom.A && navGetAttr(n, "name") == id) {
+			return n
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElementByID(c, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package epub
+
+import (
+	"errors"
+	"testing"
+)
+
+func openTOCContentTestBook(t *testing.T) *Book {
+	t.Helper()
+	return openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:toccontent</dc:identifier>
+    <dc:title>Sections</dc:title>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch3" href="ch3.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+    <itemref idref="ch3"/>
+  </spine>
+</package>`,
+		"OEBPS/nav.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><nav epub:type="toc"><ol>
+<li><a href="ch1.xhtml">Part One</a><ol>
+  <li><a href="ch1.xhtml#s1">Section 1</a></li>
+  <li><a href="ch1.xhtml#s2">Section 2</a></li>
+</ol></li>
+<li><a href="ch3.xhtml#s3">Section 3</a></li>
+</ol></nav></body></html>`,
+		"OEBPS/ch1.xhtml": `<html><body>
+<h1>Part One</h1>
+<p>Intro.</p>
+<section><h2 id="s1">Section 1</h2><p>First <img src="img/a.png" alt="a"/> section.</p><script>x()</script></section>
+<section><h2 id="s2">Section 2</h2><p>Second section.</p></section>
+</body></html>`,
+		"OEBPS/ch2.xhtml": `<html><body><p>Continued in file two.</p></body></html>`,
+		"OEBPS/ch3.xhtml": `<html><body><p>Tail of section two.</p><div><h2 id="s3">Section 3</h2><p>Third.</p></div></body></html>`,
+	})
+}
+
+func TestBook_TOCContent_Text(t *testing.T) {
+	book := openTOCContentTestBook(t)
+	toc := book.TOC()
+	part, s1, s2, s3 := toc[0], toc[0].Children[0], toc[0].Children[1], toc[1]
+
+	tests := []struct {
+		name string
+		item TOCItem
+		want string
+	}{
+		{"parent ends at first child", part, "Part One\nIntro."},
+		{"section within file", s1, "Section 1\nFirst  section."},
+		{"section across files", s2, "Section 2\nSecond section.\n\nContinued in file two.\n\nTail of section two."},
+		{"last section", s3, "Section 3\nThird."},
+	}
+	for _, tt := range tests {
+		got, err := book.TOCContent(tt.item, ContentText)
+		if err != nil {
+			t.Fatalf("%s: TOCContent() error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: TOCContent() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBook_TOCContent_HTML(t *testing.T) {
+	book := openTOCContentTestBook(t)
+	s1 := book.TOC()[0].Children[0]
+
+	got, err := book.TOCContent(s1, ContentHTML)
+	if err != nil {
+		t.Fatalf("TOCContent() error = %v", err)
+	}
+	want := `<section><h2 id="s1">Section 1</h2><p>First <img src="OEBPS/img/a.png" alt="a"/> section.</p></section>`
+	if got != want {
+		t.Errorf("TOCContent() = %q, want %q", got, want)
+	}
+}
+
+func TestBook_TOCContent_NotInSpine(t *testing.T) {
+	book := openTOCContentTestBook(t)
+	_, err := book.TOCContent(TOCItem{Href: "OEBPS/missing.xhtml"}, ContentText)
+	if !errors.Is(err, ErrNotInSpine) {
+		t.Errorf("TOCContent() error = %v, want ErrNotInSpine", err)
+	}
+}
+
+func TestBook_TOCContent_ItemNotInTOC(t *testing.T) {
+	book := openTOCContentTestBook(t)
+	got, err := book.TOCContent(TOCItem{Href: "OEBPS/ch2.xhtml", SpineIndex: 1, SpineEndIndex: 2}, ContentText)
+	if err != nil {
+		t.Fatalf("TOCContent() error = %v", err)
+	}
+	if got != "Continued in file two." {
+		t.Errorf("TOCContent() = %q, want %q", got, "Continued in file two.")
+	}
+}
+
+func TestBook_TOCContent_MissingEndFragment(t *testing.T) {
+	book := openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:toccontent-missing</dc:identifier>
+    <dc:title>Missing</dc:title>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="ch1"/><itemref idref="ch2"/></spine>
+</package>`,
+		"OEBPS/nav.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><nav epub:type="toc"><ol>
+<li><a href="ch1.xhtml">One</a></li>
+<li><a href="ch2.xhtml#gone">Two</a></li>
+</ol></nav></body></html>`,
+		"OEBPS/ch1.xhtml": `<html><body><p>Chapter one.</p></body></html>`,
+		"OEBPS/ch2.xhtml": `<html><body><p>Chapter two.</p></body></html>`,
+	})
+
+	got, err := book.TOCContent(book.TOC()[0], ContentText)
+	if err != nil {
+		t.Fatalf("TOCContent() error = %v", err)
+	}
+	if got != "Chapter one." {
+		t.Errorf("TOCContent() = %q, want %q", got, "Chapter one.")
+	}
+}
@@ -0,0 +1,197 @@
+package epub
+
+import "strings"
+
+// TOCEntry is a TOC item in the flattened, reading-order view of a TOCIndex.
+type TOCEntry struct {
+	// Title is the display text of the TOC entry.
+	Title string
+
+	// Href is the ZIP-internal target, possibly with a fragment.
+	Href string
+
+	// SpineIndex and SpineEndIndex are copied from the TOCItem.
+	SpineIndex    int
+	SpineEndIndex int
+
+	// Depth is the nesting level; top-level entries have depth 0.
+	Depth int
+
+	// Index is the position of the entry in TOCIndex.Entries.
+	Index int
+
+	// PlayOrder is the 1-based position of the entry in reading order.
+	PlayOrder int
+
+	// Parent is the Index of the parent entry, or -1 for top-level entries.
+	Parent int
+}
+
+// TOCIndex answers lookups on a table of contents: the entry for a spine
+// index or href, its ancestor chain, and the next and previous entries.
+// It is built once per Book by Book.TOCIndex and is read-only.
+type TOCIndex struct {
+	entries []TOCEntry
+	byHref  map[string]int // exact href → deepest entry
+	spine   map[string]int // ZIP path → spine index
+	bySpine []int          // spine index → entry in effect at its start, or -1
+	last    int            // last entry pointing into the spine, or -1
+}
+
+// TOCIndex returns the lookup index for the book's TOC. It is built on the
+// first call and cached; SynthesizeTOC discards the cached index.
+func (b *Book) TOCIndex() *TOCIndex {
+	if b.tocIndex == nil {
+		b.tocIndex = newTOCIndex(b.toc, b.spineIndexMap())
+	}
+	return b.tocIndex
+}
+
+// newTOCIndex flattens toc depth-first and indexes it. spineMap maps ZIP
+// paths to spine indices, as built by Book.spineIndexMap.
+func newTOCIndex(toc []TOCItem, spineMap map[string]int) *TOCIndex {
+	x := &TOCIndex{byHref: make(map[string]int), spine: spineMap}
+	var walk func(items []TOCItem, depth, parent int)
+	walk = func(items []TOCItem, depth, parent int) {
+		for _, item := range items {
+			idx := len(x.entries)
+			x.entries = append(x.entries, TOCEntry{
+				Title:         item.Title,
+				Href:          item.Href,
+				SpineIndex:    item.SpineIndex,
+				SpineEndIndex: item.SpineEndIndex,
+				Depth:         depth,
+				Index:         idx,
+				PlayOrder:     idx + 1,
+				Parent:        parent,
+			})
+			if item.Href != "" {
+				// Later entries for the same href are children or
+				// following siblings; keep the deepest.
+				if prev, ok := x.byHref[item.Href]; !ok || depth > x.entries[prev].Depth {
+					x.byHref[item.Href] = idx
+				}
+			}
+			walk(item.Children, depth+1, idx)
+		}
+	}
+	walk(toc, 0, -1)
+	x.indexSpine()
+	return x
+}
+
+// indexSpine builds the bySpine table answering ForSpineIndex. The entry in
+// effect at the start of spine item i is the later of the last entry
+// pointing to an earlier item and the last entry pointing to item i without
+// a fragment.
+func (x *TOCIndex) indexSpine() {
+	n := 0
+	for _, i := range x.spine {
+		n = max(n, i+1)
+	}
+	for _, e := range x.entries {
+		n = max(n, e.SpineIndex+1)
+	}
+	lastAt := make([]int, n)    // last entry pointing to item i
+	lastWhole := make([]int, n) // last entry pointing to item i without a fragment
+	for i := range lastAt {
+		lastAt[i], lastWhole[i] = -1, -1
+	}
+	for _, e := range x.entries {
+		if e.SpineIndex < 0 {
+			continue
+		}
+		lastAt[e.SpineIndex] = e.Index
+		if !strings.Contains(e.Href, "#") {
+			lastWhole[e.SpineIndex] = e.Index
+		}
+	}
+
+	x.bySpine = make([]int, n)
+	before := -1 // last entry pointing to an earlier item
+	for i := range x.bySpine {
+		x.bySpine[i] = max(before, lastWhole[i])
+		before = max(before, lastAt[i])
+	}
+	x.last = before
+}
+
+// Len returns the number of entries in the index.
+func (x *TOCIndex) Len() int {
+	return len(x.entries)
+}
+
+// Entries returns the flattened TOC in reading order, annotated with depth,
+// play order, and parent.
+func (x *TOCIndex) Entries() []TOCEntry {
+	return append([]TOCEntry(nil), x.entries...)
+}
+
+// ForSpineIndex returns the entry in effect at the start of spine item i:
+// the last entry in reading order that points to an earlier spine item, or
+// to spine item i itself without a fragment. Because children follow their
+// parents, this is the deepest matching entry. Reports false if no entry
+// precedes the spine item (e.g., a cover page before the first TOC entry).
+func (x *TOCIndex) ForSpineIndex(i int) (TOCEntry, bool) {
+	found := -1
+	switch {
+	case i >= len(x.bySpine):
+		found = x.last
+	case i >= 0:
+		found = x.bySpine[i]
+	}
+	if found < 0 {
+		return TOCEntry{}, false
+	}
+	return x.entries[found], true
+}
+
+// ForHref returns the deepest entry whose href equals href (a ZIP-internal
+// path, optionally with a fragment). If no entry matches exactly, the entry
+// in effect at the start of the target spine document is returned, as by
+// ForSpineIndex. Reports false if nothing matches.
+func (x *TOCIndex) ForHref(href string) (TOCEntry, bool) {
+	if idx, ok := x.byHref[href]; ok {
+		return x.entries[idx], true
+	}
+	file := hrefWithoutFragment(href)
+	if idx, ok := x.byHref[file]; ok {
+		return x.entries[idx], true
+	}
+	if spineIdx, ok := x.spine[file]; ok {
+		return x.ForSpineIndex(spineIdx)
+	}
+	return TOCEntry{}, false
+}
+
+// Breadcrumbs returns the ancestor chain of e, from the top-level entry down
+// to and including e itself.
+func (x *TOCIndex) Breadcrumbs(e TOCEntry) []TOCEntry {
+	if e.Index < 0 || e.Index >= len(x.entries) {
+		return nil
+	}
+	var chain []TOCEntry
+	for i := e.Index; i >= 0; i = x.entries[i].Parent {
+		chain = append(chain, x.entries[i])
+	}
+	for l, r := 0, len(chain)-1; l < r; l, r = l+1, r-1 {
+		chain[l], chain[r] = chain[r], chain[l]
+	}
+	return chain
+}
+
+// Next returns the entry following e in reading order.
+func (x *TOCIndex) Next(e TOCEntry) (TOCEntry, bool) {
+	if e.Index < 0 || e.Index+1 >= len(x.entries) {
+		return TOCEntry{}, false
+	}
+	return x.entries[e.Index+1], true
+}
+
+// Prev returns the entry preceding e in reading order.
+func (x *TOCIndex) Prev(e TOCEntry) (TOCEntry, bool) {
+	if e.Index <= 0 || e.Index > len(x.entries) {
+		return TOCEntry{}, false
+	}
+	return x.entries[e.Index-1], true
+}
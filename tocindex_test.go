@@ -0,0 +1,107 @@
+package epub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func entryTitles(entries []TOCEntry) []string {
+	titles := make([]string, len(entries))
+	for i, e := range entries {
+		titles[i] = e.Title
+	}
+	return titles
+}
+
+func TestBook_TOCIndex_Entries(t *testing.T) {
+	book := openTOCContentTestBook(t)
+	x := book.TOCIndex()
+
+	if x != book.TOCIndex() {
+		t.Error("TOCIndex() is not cached")
+	}
+	if x.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4", x.Len())
+	}
+	want := []TOCEntry{
+		{Title: "Part One", Href: "OEBPS/ch1.xhtml", SpineIndex: 0, SpineEndIndex: 2, Depth: 0, Index: 0, PlayOrder: 1, Parent: -1},
+		{Title: "Section 1", Href: "OEBPS/ch1.xhtml#s1", SpineIndex: 0, SpineEndIndex: 2, Depth: 1, Index: 1, PlayOrder: 2, Parent: 0},
+		{Title: "Section 2", Href: "OEBPS/ch1.xhtml#s2", SpineIndex: 0, SpineEndIndex: 2, Depth: 1, Index: 2, PlayOrder: 3, Parent: 0},
+		{Title: "Section 3", Href: "OEBPS/ch3.xhtml#s3", SpineIndex: 2, SpineEndIndex: 3, Depth: 0, Index: 3, PlayOrder: 4, Parent: -1},
+	}
+	if got := x.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTOCIndex_Lookups(t *testing.T) {
+	book := openTOCContentTestBook(t)
+	x := book.TOCIndex()
+
+	spineTests := []struct {
+		spine int
+		want  string
+		ok    bool
+	}{
+		{0, "Part One", true},
+		{1, "Section 2", true}, // continuation of the last section of ch1
+		{2, "Section 2", true}, // Section 3 starts mid-file
+		{5, "Section 3", true},
+		{-1, "", false},
+	}
+	for _, tt := range spineTests {
+		e, ok := x.ForSpineIndex(tt.spine)
+		if ok != tt.ok || e.Title != tt.want {
+			t.Errorf("ForSpineIndex(%d) = %q, %v, want %q, %v", tt.spine, e.Title, ok, tt.want, tt.ok)
+		}
+	}
+
+	hrefTests := []struct {
+		href string
+		want string
+		ok   bool
+	}{
+		{"OEBPS/ch1.xhtml#s2", "Section 2", true},
+		{"OEBPS/ch3.xhtml#s3", "Section 3", true},
+		{"OEBPS/ch1.xhtml#unknown", "Part One", true},
+		{"OEBPS/ch2.xhtml#p4", "Section 2", true},
+		{"OEBPS/nowhere.xhtml", "", false},
+	}
+	for _, tt := range hrefTests {
+		e, ok := x.ForHref(tt.href)
+		if ok != tt.ok || e.Title != tt.want {
+			t.Errorf("ForHref(%q) = %q, %v, want %q, %v", tt.href, e.Title, ok, tt.want, tt.ok)
+		}
+	}
+
+	s2, _ := x.ForHref("OEBPS/ch1.xhtml#s2")
+	if got := entryTitles(x.Breadcrumbs(s2)); !reflect.DeepEqual(got, []string{"Part One", "Section 2"}) {
+		t.Errorf("Breadcrumbs() = %v", got)
+	}
+	if next, ok := x.Next(s2); !ok || next.Title != "Section 3" {
+		t.Errorf("Next() = %q, %v, want Section 3", next.Title, ok)
+	}
+	if prev, ok := x.Prev(s2); !ok || prev.Title != "Section 1" {
+		t.Errorf("Prev() = %q, %v, want Section 1", prev.Title, ok)
+	}
+	first := x.Entries()[0]
+	if _, ok := x.Prev(first); ok {
+		t.Error("Prev(first) ok = true, want false")
+	}
+	if _, ok := x.Next(x.Entries()[x.Len()-1]); ok {
+		t.Error("Next(last) ok = true, want false")
+	}
+}
+
+func TestTOCIndex_Empty(t *testing.T) {
+	x := newTOCIndex(nil, nil)
+	if x.Len() != 0 || x.Entries() != nil {
+		t.Errorf("empty index Len() = %d, Entries() = %v", x.Len(), x.Entries())
+	}
+	if _, ok := x.ForSpineIndex(0); ok {
+		t.Error("ForSpineIndex() ok = true on empty index")
+	}
+	if got := x.Breadcrumbs(TOCEntry{Index: 3}); got != nil {
+		t.Errorf("Breadcrumbs() = %v, want nil", got)
+	}
+}
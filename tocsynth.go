@@ -0,0 +1,315 @@
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// synthesizedTitleMaxRunes caps the length of a TOC title taken from the
+// first line of a document that has no heading or <title>.
+const synthesizedTitleMaxRunes = 80
+
+// SynthesizeTOC builds a table of contents from the <h1>–<h6> headings of
+// the spine documents when the book declares none (no nav document and no
+// NCX, or both empty). It is opt-in: call it after Open or NewReader.
+//
+// Headings are nested by level. Documents without headings contribute one
+// entry titled by their <title> element or, failing that, their first line
+// of text. Headings that lack an id attribute are given a generated one so
+// that each entry can link to its heading; the generated ids are inserted
+// when the chapter content is read through Chapter methods.
+//
+// The synthesized TOC is returned by TOC, counted by HasTOC, and used for
+// Chapter titles; TOCSynthesized reports true afterwards. SynthesizeTOC
+// reports whether a TOC was synthesized. It does nothing and returns false
+// when the book already has a TOC.
+func (b *Book) SynthesizeTOC() bool {
+	if len(b.toc) > 0 {
+		return false
+	}
+
+	entries, anchors := b.collectTOCHeadings()
+	if len(entries) == 0 {
+		return false
+	}
+
+	toc := nestTOCHeadings(entries)
+	assignSpineIndices(toc, b.spineIndexMap())
+	computeSpineRanges(toc, len(b.spine))
+
+	b.toc = toc
+	b.tocIndex = nil
+	b.tocAnchors = anchors
+	b.tocSynthesized = true
+	b.warnings = append(b.warnings, "no table of contents found; synthesized from headings")
+
+	// Refresh titles of already built chapters.
+	if b.chapters != nil {
+		titles := buildTOCTitleMap(b.toc)
+		for i := range b.chapters {
+			b.chapters[i].Title = titles[b.chapters[i].Href]
+			b.chapters[i].TitleSource = TitleSourceNone
+			if b.chapters[i].Title != "" {
+				b.chapters[i].TitleSource = TitleSourceTOC
+			}
+		}
+	}
+	return true
+}
+
+// TOCSynthesized reports whether the table of contents returned by TOC was
+// generated by SynthesizeTOC rather than read from a nav document or NCX.
+func (b *Book) TOCSynthesized() bool {
+	return b.tocSynthesized
+}
+
+// tocHeading is a heading found while synthesizing a TOC.
+type tocHeading struct {
+	level int
+	title string
+	href  string
+}
+
+// collectTOCHeadings scans every spine document in order and returns the
+// TOC entries, plus the ids to insert per document (ZIP path → heading
+// ordinal → id).
+func (b *Book) collectTOCHeadings() ([]tocHeading, map[string]map[int]string) {
+	var entries []tocHeading
+	anchors := make(map[string]map[int]string)
+	fallbacks := make(map[int]bool) // indices into entries of title/first-line entries
+	minLevel := 0
+
+	for _, si := range b.spine {
+		href := b.resolveOPFPath(si.Href)
+		if href == "" {
+			continue
+		}
+		data, err := b.readContent(href)
+		if err != nil {
+			continue
+		}
+
+		headings, ids := scanHeadings(data)
+		if len(headings) == 0 {
+			title := documentTitle(data)
+			if title == "" {
+				continue
+			}
+			fallbacks[len(entries)] = true
+			entries = append(entries, tocHeading{title: title, href: href})
+			continue
+		}
+
+		for i, h := range headings {
+			if minLevel == 0 || h.level < minLevel {
+				minLevel = h.level
+			}
+			entry := tocHeading{level: h.level, title: h.text, href: href}
+			switch {
+			case h.id != "":
+				entry.href = href + "#" + h.id
+			case i > 0:
+				id := uniqueHeadingID(ids, len(entries))
+				if anchors[href] == nil {
+					anchors[href] = make(map[int]string)
+				}
+				anchors[href][h.ordinal] = id
+				entry.href = href + "#" + id
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	// Entries without a heading sit at the top level of the TOC.
+	if minLevel == 0 {
+		minLevel = 1
+	}
+	for i := range fallbacks {
+		entries[i].level = minLevel
+	}
+	return entries, anchors
+}
+
+// scannedHeading is a single <h1>–<h6> element found in a document.
+type scannedHeading struct {
+	ordinal int // position among all heading start tags in the document
+	level   int
+	id      string
+	text    string
+}
+
+// scanHeadings tokenizes an XHTML document and returns its non-empty
+// headings in document order, together with the set of ids used anywhere in
+// the document. An image-only heading takes its text from the alt attribute.
+func scanHeadings(data []byte) ([]scannedHeading, map[string]bool) {
+	var headings []scannedHeading
+	ids := make(map[string]bool)
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	ordinal := 0
+	var current *scannedHeading
+	var text strings.Builder
+
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			return headings, ids
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tn, hasAttr := tokenizer.TagName()
+			a := atom.Lookup(tn)
+			var id, alt string
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = tokenizer.TagAttr()
+				switch string(key) {
+				case "id":
+					id = string(val)
+				case "alt":
+					alt = string(val)
+				}
+			}
+			if id != "" {
+				ids[id] = true
+			}
+			if level := headingLevel(a); level > 0 {
+				if tt == html.StartTagToken && current == nil {
+					current = &scannedHeading{ordinal: ordinal, level: level, id: id}
+					text.Reset()
+				}
+				ordinal++
+				continue
+			}
+			if current != nil && a == atom.Img {
+				text.WriteString(" " + alt + " ")
+			}
+		case html.EndTagToken:
+			tn, _ := tokenizer.TagName()
+			if current != nil && headingLevel(atom.Lookup(tn)) == current.level {
+				current.text = strings.TrimSpace(collapseWhitespace(text.String()))
+				if current.text != "" {
+					headings = append(headings, *current)
+				}
+				current = nil
+			}
+		case html.TextToken:
+			if current != nil {
+				text.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+// documentTitle returns the <title> text of an XHTML document or, if it has
+// none, the first line of its text content truncated to
+// synthesizedTitleMaxRunes. Returns "" for documents without any text.
+func documentTitle(data []byte) string {
+	if title := documentTitleElement(data); title != "" {
+		return title
+	}
+
+	text, err := extractText(data)
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(text, "\n")
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) > synthesizedTitleMaxRunes {
+		line = strings.TrimSpace(string([]rune(line)[:synthesizedTitleMaxRunes])) + "…"
+	}
+	return line
+}
+
+// documentTitleElement returns the text of the <title> element of an XHTML
+// document, or "" if it has none.
+func documentTitleElement(data []byte) string {
+	doc, err := html.Parse(bytes.NewReader(normalizeSelfClosingSkipTags(data)))
+	if err != nil {
+		return ""
+	}
+	if t := findElement(doc, atom.Title); t != nil {
+		return strings.TrimSpace(collapseWhitespace(nodeTextContent(t)))
+	}
+	return ""
+}
+
+// uniqueHeadingID returns an id of the form "toc-N" that is not yet used in
+// the document, and records it in ids.
+func uniqueHeadingID(ids map[string]bool, n int) string {
+	id := fmt.Sprintf("toc-%d", n+1)
+	for i := 2; ids[id]; i++ {
+		id = fmt.Sprintf("toc-%d-%d", n+1, i)
+	}
+	ids[id] = true
+	return id
+}
+
+// nestTOCHeadings turns a flat list of headings into a TOC tree: every entry
+// adopts the following entries of a deeper level as its children.
+func nestTOCHeadings(entries []tocHeading) []TOCItem {
+	var items []TOCItem
+	for i := 0; i < len(entries); {
+		j := i + 1
+		for j < len(entries) && entries[j].level > entries[i].level {
+			j++
+		}
+		items = append(items, TOCItem{
+			Title:         entries[i].title,
+			Href:          entries[i].href,
+			Children:      nestTOCHeadings(entries[i+1 : j]),
+			SpineIndex:    -1,
+			SpineEndIndex: -1,
+		})
+		i = j
+	}
+	return items
+}
+
+// insertHeadingIDs adds id attributes to the heading start tags of data
+// whose ordinal (as counted by scanHeadings) appears in ids. All other bytes
+// are copied unchanged.
+func insertHeadingIDs(data []byte, ids map[int]string) []byte {
+	var out bytes.Buffer
+	out.Grow(len(data) + 16*len(ids))
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	ordinal := 0
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			out.Write(tokenizer.Raw())
+			return out.Bytes()
+		}
+		raw := tokenizer.Raw()
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			tn, _ := tokenizer.TagName()
+			if headingLevel(atom.Lookup(tn)) > 0 {
+				if id, ok := ids[ordinal]; ok {
+					raw = insertTagAttr(raw, "id", id)
+				}
+				ordinal++
+			}
+		}
+		out.Write(raw)
+	}
+}
+
+// insertTagAttr inserts key="val" into the raw start tag, just before its
+// closing ">" or "/>".
+func insertTagAttr(raw []byte, key, val string) []byte {
+	end := len(raw) - 1
+	if end > 0 && raw[end-1] == '/' {
+		end--
+	}
+	attr := fmt.Sprintf(` %s="%s"`, key, html.EscapeString(val))
+	out := make([]byte, 0, len(raw)+len(attr))
+	out = append(out, bytes.TrimRight(raw[:end], " \t\r\n")...)
+	out = append(out, attr...)
+	out = append(out, raw[end:]...)
+	return out
+}
@@ -0,0 +1,160 @@
+package epub
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const synthTestOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:synth</dc:identifier>
+    <dc:title>No TOC</dc:title>
+  </metadata>
+  <manifest>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="end" href="end.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="cover"/>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+    <itemref idref="end"/>
+  </spine>
+</package>`
+
+func openSynthTestBook(t *testing.T) *Book {
+	t.Helper()
+	return openPageListTestBook(t, map[string]string{
+		"OEBPS/content.opf": synthTestOPF,
+		"OEBPS/cover.xhtml": `<html><head><title>Cover</title></head><body><img src="c.jpg" alt=""/></body></html>`,
+		"OEBPS/ch1.xhtml": `<html><head><title>ignored</title></head><body>
+<h1>Chapter  One</h1>
+<p>Text</p>
+<h2 id="s1">Section A</h2>
+<h3>Deep</h3>
+<h2 class="x">Section B</h2>
+</body></html>`,
+		"OEBPS/ch2.xhtml": `<html><body>
+<h1 id="c2"><img src="t.png" alt="Chapter Two"/></h1>
+<h2></h2>
+<h2>Section <em>C</em></h2>
+</body></html>`,
+		"OEBPS/end.xhtml": `<html><body><p>The End, and thanks for reading.</p><p>More text.</p></body></html>`,
+	})
+}
+
+func TestBook_SynthesizeTOC(t *testing.T) {
+	book := openSynthTestBook(t)
+
+	if book.HasTOC() {
+		t.Fatal("HasTOC() = true before synthesis")
+	}
+	if chapters := book.Chapters(); chapters[1].Title != "" {
+		t.Fatalf("Chapters()[1].Title = %q before synthesis, want empty", chapters[1].Title)
+	}
+	if !book.SynthesizeTOC() {
+		t.Fatal("SynthesizeTOC() = false, want true")
+	}
+	if !book.TOCSynthesized() || !book.HasTOC() {
+		t.Errorf("TOCSynthesized() = %v, HasTOC() = %v, want both true", book.TOCSynthesized(), book.HasTOC())
+	}
+
+	want := []TOCItem{
+		{Title: "Cover", Href: "OEBPS/cover.xhtml", SpineIndex: 0, SpineEndIndex: 1},
+		{Title: "Chapter One", Href: "OEBPS/ch1.xhtml", SpineIndex: 1, SpineEndIndex: 2, Children: []TOCItem{
+			{Title: "Section A", Href: "OEBPS/ch1.xhtml#s1", SpineIndex: 1, SpineEndIndex: 2, Children: []TOCItem{
+				{Title: "Deep", Href: "OEBPS/ch1.xhtml#toc-4", SpineIndex: 1, SpineEndIndex: 2},
+			}},
+			{Title: "Section B", Href: "OEBPS/ch1.xhtml#toc-5", SpineIndex: 1, SpineEndIndex: 2},
+		}},
+		{Title: "Chapter Two", Href: "OEBPS/ch2.xhtml#c2", SpineIndex: 2, SpineEndIndex: 3, Children: []TOCItem{
+			{Title: "Section C", Href: "OEBPS/ch2.xhtml#toc-7", SpineIndex: 2, SpineEndIndex: 3},
+		}},
+		{Title: "The End, and thanks for reading.", Href: "OEBPS/end.xhtml", SpineIndex: 3, SpineEndIndex: 4},
+	}
+	if got := book.TOC(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TOC() = %+v, want %+v", got, want)
+	}
+
+	chapters := book.Chapters()
+	wantTitles := []string{"Cover", "Chapter One", "Chapter Two", "The End, and thanks for reading."}
+	for i, w := range wantTitles {
+		if chapters[i].Title != w {
+			t.Errorf("Chapters()[%d].Title = %q, want %q", i, chapters[i].Title, w)
+		}
+	}
+
+	raw, err := chapters[1].RawContent()
+	if err != nil {
+		t.Fatalf("RawContent() error = %v", err)
+	}
+	for _, s := range []string{`<h1>Chapter  One</h1>`, `<h2 id="s1">`, `<h3 id="toc-4">Deep</h3>`, `<h2 class="x" id="toc-5">`} {
+		if !strings.Contains(string(raw), s) {
+			t.Errorf("RawContent() missing %q:\n%s", s, raw)
+		}
+	}
+
+	// The archive itself is untouched.
+	orig, err := book.ReadFile("OEBPS/ch1.xhtml")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(orig), "toc-4") {
+		t.Error("ReadFile() returned content with inserted ids")
+	}
+	if !containsWarning(book.Warnings(), "synthesized") {
+		t.Errorf("Warnings() = %v, want synthesis warning", book.Warnings())
+	}
+}
+
+func TestBook_SynthesizeTOC_ExistingTOC(t *testing.T) {
+	book, err := Open(buildChapterTestEPub(t))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer book.Close()
+
+	before := book.TOC()
+	if book.SynthesizeTOC() {
+		t.Error("SynthesizeTOC() = true for a book with a TOC")
+	}
+	if book.TOCSynthesized() {
+		t.Error("TOCSynthesized() = true, want false")
+	}
+	if !reflect.DeepEqual(book.TOC(), before) {
+		t.Error("SynthesizeTOC() modified an existing TOC")
+	}
+}
+
+func TestNestTOCHeadings_SkippedLevels(t *testing.T) {
+	entries := []tocHeading{
+		{level: 2, title: "A", href: "a"},
+		{level: 4, title: "A.1", href: "a1"},
+		{level: 3, title: "A.2", href: "a2"},
+		{level: 1, title: "B", href: "b"},
+	}
+	got := nestTOCHeadings(entries)
+	want := []TOCItem{
+		{Title: "A", Href: "a", SpineIndex: -1, SpineEndIndex: -1, Children: []TOCItem{
+			{Title: "A.1", Href: "a1", SpineIndex: -1, SpineEndIndex: -1},
+			{Title: "A.2", Href: "a2", SpineIndex: -1, SpineEndIndex: -1},
+		}},
+		{Title: "B", Href: "b", SpineIndex: -1, SpineEndIndex: -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nestTOCHeadings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestInsertHeadingIDs(t *testing.T) {
+	in := `<?xml version="1.0"?><html><body><h1>One</h1><h2 class="a" >Two</h2><h3/><p>x</p></body></html>`
+	got := string(insertHeadingIDs([]byte(in), map[int]string{1: "t&2", 2: "t3"}))
+	want := `<?xml version="1.0"?><html><body><h1>One</h1><h2 class="a" id="t&amp;2">Two</h2><h3 id="t3"/><p>x</p></body></html>`
+	if got != want {
+		t.Errorf("insertHeadingIDs() =\n%s\nwant\n%s", got, want)
+	}
+}
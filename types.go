@@ -1,5 +1,7 @@
 package epub
 
+import "time"
+
 // Metadata holds the Dublin Core and other metadata extracted from the OPF file.
 type Metadata struct {
 	// Version is the ePub specification version (e.g., "2.0", "3.0").
@@ -8,7 +10,13 @@ type Metadata struct {
 	// Titles contains all dc:title values. The first entry is the primary title.
 	Titles []string
 
+	// TitleDetails contains the same titles as Titles, in the same order,
+	// with their ePub 3 title-type, language, direction, and alternate-script
+	// refinements. Use MainTitle and Subtitle for typed lookups.
+	TitleDetails []Title
+
 	// Authors contains all dc:creator entries with their roles and file-as values.
+	// Creators declared twice (ePub 2 attributes and ePub 3 refines) are merged.
 	Authors []Author
 
 	// Language contains all dc:language values (BCP 47 tags, e.g., "en", "zh-CN").
@@ -23,6 +31,19 @@ type Metadata struct {
 	// Date is the dc:date value (publication date as raw string).
 	Date string
 
+	// Published is the parsed publication date: the ePub 2 dc:date with
+	// opf:event="publication", or else the first dc:date without an event.
+	Published PartialDate
+
+	// Modified is the parsed last-modification date: the ePub 3
+	// dcterms:modified meta, or else the ePub 2 dc:date with
+	// opf:event="modification".
+	Modified PartialDate
+
+	// Dates contains every dc:date entry in document order, parsed and
+	// tagged with its ePub 2 opf:event attribute.
+	Dates []EventDate
+
 	// Description is the dc:description value.
 	Description string
 
@@ -34,6 +55,127 @@ type Metadata struct {
 
 	// Source is the dc:source value.
 	Source string
+
+	// Collections contains the series and sets the publication belongs to.
+	// Entries come from ePub 3 belongs-to-collection metadata, Calibre's
+	// calibre:series/calibre:series_index metas, and ePub 3.0.1 <collection>
+	// elements, in that order of precedence.
+	Collections []Collection
+
+	// Accessibility holds the schema.org accessibility and conformance
+	// metadata declared in the package document.
+	Accessibility Accessibility
+}
+
+// Accessibility holds the accessibility metadata of a publication, as
+// described by EPUB Accessibility 1.1 and the schema.org vocabulary.
+type Accessibility struct {
+	// AccessModes contains the schema:accessMode values (e.g., "textual", "visual").
+	AccessModes []string
+
+	// AccessModesSufficient contains the schema:accessModeSufficient values.
+	// Each entry is one set of access modes sufficient to consume the
+	// content (e.g., ["textual", "visual"]).
+	AccessModesSufficient [][]string
+
+	// Features contains the schema:accessibilityFeature values
+	// (e.g., "alternativeText", "structuralNavigation").
+	Features []string
+
+	// Hazards contains the schema:accessibilityHazard values
+	// (e.g., "none", "flashing").
+	Hazards []string
+
+	// Summary is the schema:accessibilitySummary text.
+	Summary string
+
+	// ConformsTo contains the dcterms:conformsTo values, from either
+	// <meta> or <link> elements (e.g., "EPUB Accessibility 1.1 - WCAG 2.1 Level AA").
+	ConformsTo []string
+
+	// CertifiedBy is the a11y:certifiedBy value.
+	CertifiedBy string
+
+	// CertifierCredential is the a11y:certifierCredential value.
+	CertifierCredential string
+
+	// CertifierReport is the a11y:certifierReport value or link href.
+	CertifierReport string
+}
+
+// Collection represents a series or set that a publication belongs to.
+type Collection struct {
+	// Name is the collection name (e.g., "The Lord of the Rings").
+	Name string
+
+	// Type is the collection-type refinement ("series", "set") or, for
+	// <collection> elements, the role attribute. Calibre series are "series".
+	Type string
+
+	// Position is the group-position (or calibre:series_index) of the
+	// publication within the collection, e.g. 2 or 2.5.
+	// A value of -1 indicates no position was given.
+	Position float64
+
+	// ID is the xml id attribute of the element that declared the collection.
+	ID string
+
+	// Identifier is the dcterms:identifier refinement of the collection, if any.
+	Identifier string
+
+	// FileAs is the file-as refinement of the collection name, if any.
+	FileAs string
+
+	// Collections contains nested collections. For ePub 3 metadata these are
+	// the collections this collection itself belongs to (belongs-to-collection
+	// metas refining it); for <collection> elements they are its child
+	// <collection> elements.
+	Collections []Collection
+}
+
+// DatePrecision records which components of a PartialDate were present
+// in the source value.
+type DatePrecision int
+
+// Date precisions, from least to most precise.
+const (
+	// DatePrecisionNone indicates the value could not be parsed.
+	DatePrecisionNone DatePrecision = iota
+	// DatePrecisionYear indicates a year-only date ("2024").
+	DatePrecisionYear
+	// DatePrecisionMonth indicates a year-month date ("2024-06").
+	DatePrecisionMonth
+	// DatePrecisionDay indicates a complete calendar date ("2024-06-01").
+	DatePrecisionDay
+	// DatePrecisionMinute indicates a timestamp with hours and minutes.
+	DatePrecisionMinute
+	// DatePrecisionSecond indicates a timestamp with (possibly fractional) seconds.
+	DatePrecisionSecond
+)
+
+// PartialDate is a W3CDTF / ISO 8601 date that may omit trailing components.
+// Missing components default to the earliest possible value (January, day 1,
+// midnight UTC), so Time can be used directly for sorting.
+type PartialDate struct {
+	// Time is the parsed instant. Values without a zone designator are UTC.
+	// Zero when Precision is DatePrecisionNone.
+	Time time.Time
+
+	// Precision records which components were present in Raw.
+	Precision DatePrecision
+
+	// Raw is the original, whitespace-trimmed value.
+	Raw string
+}
+
+// EventDate is a dc:date entry together with its ePub 2 event attribute.
+type EventDate struct {
+	// Event is the opf:event attribute value (e.g., "publication",
+	// "creation", "modification"), or empty when absent.
+	Event string
+
+	// Date is the parsed date value.
+	Date PartialDate
 }
 
 // Author represents a dc:creator entry with optional file-as and role attributes.
@@ -44,8 +186,63 @@ type Author struct {
 	// FileAs is the opf:file-as attribute value (e.g., "Dickens, Charles").
 	FileAs string
 
-	// Role is the opf:role attribute value (e.g., "aut", "edt", "trl").
-	Role string
+	// Role is the MARC relator code from the opf:role attribute or the
+	// ePub 3 role refinement (e.g., "aut", "edt", "trl"), normalised by
+	// ParseRole. Use Role.Name for a human-readable label.
+	Role Role
+
+	// Language is the xml:lang of the name, inherited from the package
+	// element when not set on dc:creator itself.
+	Language string
+
+	// Dir is the text direction of the name ("ltr" or "rtl"), if declared.
+	Dir string
+
+	// AlternateScripts contains ePub 3 alternate-script renderings of the
+	// name (e.g., the romanised form of a Japanese name).
+	AlternateScripts []AlternateScript
+}
+
+// Title represents a dc:title entry with its ePub 3 refinements.
+type Title struct {
+	// Value is the title text.
+	Value string
+
+	// Type is the ePub 3 title-type refinement: "main", "subtitle", "short",
+	// "collection", "edition", or "expanded". Empty when not declared.
+	Type string
+
+	// DisplaySeq is the ePub 3 display-seq refinement, or 0 when absent.
+	DisplaySeq int
+
+	// FileAs is the file-as refinement used for sorting, if any.
+	FileAs string
+
+	// Language is the xml:lang of the title, inherited from the package
+	// element when not set on dc:title itself.
+	Language string
+
+	// Dir is the text direction of the title ("ltr" or "rtl"), if declared.
+	Dir string
+
+	// ID is the xml id attribute of the dc:title element.
+	ID string
+
+	// AlternateScripts contains alternate-script renderings of the title.
+	AlternateScripts []AlternateScript
+}
+
+// AlternateScript is an ePub 3 alternate-script refinement: the same
+// value rendered in another script or language.
+type AlternateScript struct {
+	// Value is the alternate rendering.
+	Value string
+
+	// Language is the xml:lang of the alternate rendering (e.g., "ja-Latn").
+	Language string
+
+	// Dir is the text direction of the alternate rendering, if declared.
+	Dir string
 }
 
 // Identifier represents a dc:identifier entry.
@@ -81,14 +278,229 @@ type TOCItem struct {
 	// and SpineEndIndex=3, the entry covers spine items 0, 1, and 2.
 	// A value of -1 indicates no spine association was found.
 	SpineEndIndex int
+
+	// Type is the epub:type of the entry's link (e.g., "bodymatter" in
+	// landmarks), or empty when it has none.
+	Type string
+}
+
+// GuideReference is an ePub 2 <guide> reference.
+type GuideReference struct {
+	// Type is the guide type (e.g., "cover", "toc", "text", "title-page").
+	Type string
+
+	// Title is the human-readable label of the reference.
+	Title string
+
+	// Href is the ZIP-internal target path, possibly with a fragment.
+	Href string
+}
+
+// StructuralSemantic is a publication location identified by an EPUB
+// Structural Semantics Vocabulary term, such as the cover, the table of
+// contents, or the start of the body matter.
+type StructuralSemantic struct {
+	// Type is the EPUB Structural Semantics term (e.g., "cover", "toc",
+	// "bodymatter", "titlepage", "copyright-page").
+	Type string
+
+	// Title is the label of the location, if known.
+	Title string
+
+	// Href is the ZIP-internal target path, possibly with a fragment.
+	Href string
+
+	// SpineIndex is the index of the spine item containing the location.
+	// A value of -1 indicates no spine association was found.
+	SpineIndex int
+
+	// Source is where the location was found: SemanticSourceLandmarks,
+	// SemanticSourceGuide, or SemanticSourceContent.
+	Source string
+}
+
+// NavList is a single navigation list from the nav document (a <nav>
+// element) or the NCX (a <navList> element), such as a list of
+// illustrations or tables.
+type NavList struct {
+	// Type is the nav's epub:type value (e.g., "toc", "landmarks", "loi",
+	// "lot", "loa") or, for NCX navLists, the class attribute. Empty when the
+	// nav carries no type.
+	Type string
+
+	// Title is the text of the nav's heading, or the NCX navList's navLabel.
+	Title string
+
+	// Hidden reports whether the nav carries the hidden attribute, i.e. it is
+	// intended for machine navigation only.
+	Hidden bool
+
+	// Items is the tree of entries in the list.
+	Items []TOCItem
+}
+
+// PageTarget maps a print page label to a location in the publication.
+type PageTarget struct {
+	// Label is the print page label (e.g., "42", "xii").
+	Label string
+
+	// Href is the ZIP-internal target path, usually with a fragment
+	// (e.g., "OEBPS/chapter03.xhtml#page42").
+	Href string
+
+	// SpineIndex is the index of the spine item containing the page break.
+	// A value of -1 indicates no spine association was found.
+	SpineIndex int
+
+	// Type is the NCX pageTarget type ("front", "normal", "special").
+	// Empty for page lists from the nav document or from content scanning.
+	Type string
+}
+
+// ChapterRole classifies a spine item by its function in the publication.
+type ChapterRole string
+
+// Chapter roles.
+const (
+	ChapterRoleUnknown       ChapterRole = ""
+	ChapterRoleCover         ChapterRole = "cover"
+	ChapterRoleTitlePage     ChapterRole = "titlepage"
+	ChapterRoleCopyright     ChapterRole = "copyright"
+	ChapterRoleDedication    ChapterRole = "dedication"
+	ChapterRoleTOC           ChapterRole = "toc"
+	ChapterRoleForeword      ChapterRole = "foreword"
+	ChapterRoleBody          ChapterRole = "body"
+	ChapterRoleAppendix      ChapterRole = "appendix"
+	ChapterRoleNotes         ChapterRole = "notes"
+	ChapterRoleIndex         ChapterRole = "index"
+	ChapterRoleColophon      ChapterRole = "colophon"
+	ChapterRoleAdvertisement ChapterRole = "advertisement"
+)
+
+// TitleSource identifies where a chapter title was taken from. The
+// constants are listed from most to least reliable.
+type TitleSource string
+
+// Chapter title sources.
+const (
+	TitleSourceNone     TitleSource = ""
+	TitleSourceTOC      TitleSource = "toc"       // TOC entry pointing to the chapter file
+	TitleSourceTOCRange TitleSource = "toc-range" // TOC entry whose spine range covers the chapter
+	TitleSourceDocument TitleSource = "title"     // XHTML <title> element
+	TitleSourceHeading  TitleSource = "heading"   // first <h1>–<h6> heading
+	TitleSourceLandmark TitleSource = "landmark"  // ePub 3 landmarks label
+	TitleSourceGuide    TitleSource = "guide"     // ePub 2 guide reference title
+)
+
+// BlockType identifies the kind of a Block.
+type BlockType string
+
+// Block types.
+const (
+	BlockHeading   BlockType = "heading"   // <h1>–<h6>
+	BlockParagraph BlockType = "paragraph" // <p>, or a run of loose inline content
+	BlockList      BlockType = "list"      // <ul>, <ol>, <dl>
+	BlockListItem  BlockType = "list-item" // <li>, <dt>, <dd>
+	BlockQuote     BlockType = "quote"     // <blockquote>
+	BlockCode      BlockType = "code"      // <pre>
+	BlockTable     BlockType = "table"     // <table>
+	BlockTableRow  BlockType = "row"       // <tr>
+	BlockTableCell BlockType = "cell"      // <td>, <th>
+	BlockFigure    BlockType = "figure"    // <figure>
+	BlockCaption   BlockType = "caption"   // <figcaption>, <caption>
+	BlockImage     BlockType = "image"     // <img>, SVG <image>
+	BlockSection   BlockType = "section"   // <section>, <article>, <aside>, <nav>, <header>, <footer>, <main>, and <div> or image-only <p> with an id or epub:type
+	BlockRule      BlockType = "rule"      // <hr>
+)
+
+// Note is a footnote, endnote, or rearnote together with the reference
+// that points to it.
+type Note struct {
+	// Label is the reference marker without brackets (e.g., "1", "*").
+	Label string
+
+	// Type is the epub:type of the note body ("footnote", "endnote",
+	// "rearnote", or "note"), or empty for notes found by link patterns.
+	Type string
+
+	// RefHref is the ZIP-internal path of the document containing the
+	// reference, with a "#id" fragment when the reference has an id.
+	RefHref string
+
+	// RefSpineIndex is the spine position of the reference document, or -1.
+	RefSpineIndex int
+
+	// Href is the ZIP-internal path and fragment of the note body.
+	Href string
+
+	// SpineIndex is the spine position of the note document, or -1.
+	SpineIndex int
+
+	// Text is the plain text of the note body, without back-links to the
+	// reference.
+	Text string
+}
+
+// Block is a node of the structured content model returned by
+// Chapter.Blocks.
+//
+// Blocks that only hold inline content carry it in Text; blocks with nested
+// block content carry it in Children instead. Leading inline content of
+// such a block (e.g., the text of a list item before a nested list) becomes
+// an anonymous BlockParagraph child.
+type Block struct {
+	// Type is the kind of block.
+	Type BlockType
+
+	// Level is the heading level (1–6) for BlockHeading, and the nesting
+	// depth (1 for a top-level list) for BlockList and BlockListItem.
+	Level int
+
+	// Text is the whitespace-collapsed inline text. <br> produces a line
+	// break. For BlockCode the text is kept verbatim.
+	Text string
+
+	// ID is the element's id attribute.
+	ID string
+
+	// EpubType is the element's epub:type attribute.
+	EpubType string
+
+	// Ordered is true for BlockList from an <ol>.
+	Ordered bool
+
+	// Number is the item number of a BlockListItem in an ordered list,
+	// honouring the start, reversed, and value attributes. It is 0 in
+	// unordered lists.
+	Number int
+
+	// Header is true for a BlockTableCell from a <th>.
+	Header bool
+
+	// Src is the ZIP-internal path (or external URL) of a BlockImage.
+	Src string
+
+	// Alt is the alternative text of a BlockImage.
+	Alt string
+
+	// Children holds nested blocks: list items, table rows and cells,
+	// figure images and captions, section and quote content, and images
+	// inside a paragraph.
+	Children []Block
 }
 
 // Chapter represents a spine item with methods for content access.
 // Content is loaded lazily from the underlying ePub archive.
 type Chapter struct {
 	// Title is the chapter title derived from the TOC (empty if not in TOC).
+	// Book.ResolveChapterTitles fills in titles for chapters the TOC misses.
 	Title string
 
+	// TitleSource records where Title came from: TitleSourceTOC for TOC
+	// titles, one of the other TitleSource constants after
+	// ResolveChapterTitles, or TitleSourceNone when Title is empty.
+	TitleSource TitleSource
+
 	// Href is the content file path within the ePub archive.
 	Href string
 
@@ -99,18 +511,110 @@ type Chapter struct {
 	Linear bool
 
 	// IsLicense indicates whether this chapter is a Project Gutenberg license page.
-	// Detection is based on known Gutenberg license patterns in the text content.
+	// Detection is based on known Gutenberg license patterns in the text content;
+	// a chapter that also holds text between the "*** START/END OF THE PROJECT
+	// GUTENBERG EBOOK" markers is not a license page.
 	IsLicense bool
 
+	// IsBoilerplate indicates whether a registered BoilerplateDetector found
+	// the whole chapter to be boilerplate (see Book.AddBoilerplateDetector).
+	// It is populated by Book.ContentChapters.
+	IsBoilerplate bool
+
+	// Role classifies the chapter as front matter, body, or back matter.
+	// It is populated by Book.BodyChapters (or Book.ClassifyChapters);
+	// until then it is ChapterRoleUnknown.
+	Role ChapterRole
+
+	// Properties contains the ePub 3 spine itemref properties
+	// (e.g., "page-spread-left", "rendition:layout-pre-paginated").
+	Properties []string
+
+	// PageSpread is the synthetic spread position requested by the itemref:
+	// "left", "right", "center", or empty when unspecified.
+	PageSpread string
+
+	// Rendition is the effective rendition for this chapter: the global
+	// rendition:* metadata with any per-itemref rendition overrides applied.
+	Rendition Rendition
+
 	// book is a reference to the parent Book for lazy content loading.
 	// This will be set when chapters are constructed during parsing.
 	book bookReader
 }
 
+// Rendition holds ePub 3 rendering properties (EPUB 3 Fixed Layout and the
+// rendition:* vocabulary). Empty fields were not declared; reading systems
+// then apply the specification defaults (reflowable, auto).
+type Rendition struct {
+	// Layout is "reflowable" or "pre-paginated" (fixed layout).
+	Layout string
+
+	// Orientation is "auto", "landscape", or "portrait".
+	Orientation string
+
+	// Spread is "none", "landscape", "portrait", "both", or "auto".
+	Spread string
+
+	// Flow is "paginated", "scrolled-continuous", "scrolled-doc", or "auto".
+	Flow string
+
+	// Viewport is the raw (deprecated) rendition:viewport value,
+	// e.g. "width=1200, height=1600".
+	Viewport string
+
+	// PageProgressionDirection is the spine page-progression-direction:
+	// "ltr", "rtl", or "default". Empty when not declared.
+	PageProgressionDirection string
+}
+
+// DisplayOptions is the normalised view of vendor display options: Apple's
+// META-INF/com.apple.ibooks.display-options.xml, Kobo's
+// META-INF/com.kobobooks.display-options.xml, and the legacy fixed-layout
+// metas used by ePub 2 titles (fixed-layout, original-resolution, ...).
+// Where the OPF declares rendition:* properties, those take precedence.
+type DisplayOptions struct {
+	// FixedLayout reports whether pages are pre-paginated.
+	FixedLayout bool
+
+	// OpenToSpread reports whether the book should open in two-page spread view.
+	OpenToSpread bool
+
+	// SpecifiedFonts reports whether embedded fonts should be used.
+	SpecifiedFonts bool
+
+	// Interactive reports whether the book contains interactive content.
+	Interactive bool
+
+	// OrientationLock is "landscape-only", "portrait-only", "none", or empty.
+	OrientationLock string
+
+	// OriginalResolution is the page size declared by the
+	// original-resolution meta (e.g., "1024x768"), if any.
+	OriginalResolution Viewport
+
+	// BookType is the book-type meta value (e.g., "comic", "children").
+	BookType string
+
+	// Sources lists the sources that contributed options, in precedence
+	// order: "opf" (rendition:* metadata), "apple", "kobo", "opf-meta".
+	Sources []string
+}
+
+// Viewport holds the initial containing block dimensions of a fixed-layout
+// content document, in CSS pixels.
+type Viewport struct {
+	Width  int
+	Height int
+}
+
 // bookReader is a private interface for lazy content loading from the ePub archive.
 // It is implemented by the Book type defined in epub.go.
 type bookReader interface {
 	readFile(path string) ([]byte, error)
+	spineIndexOf(href string) int
+	boilerplateDetectors() []BoilerplateDetector
+	bookNotes() []Note
 }
 
 // CoverImage holds the detected cover image data.
@@ -142,6 +646,10 @@ type spineItem struct {
 
 	// IDRef is the idref attribute value from the <itemref> element.
 	IDRef string
+
+	// Properties contains the space-separated itemref properties (ePub 3,
+	// e.g., "page-spread-left rendition:layout-pre-paginated").
+	Properties string
 }
 
 // manifestItem represents an entry in the OPF <manifest> element.
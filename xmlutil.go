@@ -0,0 +1,171 @@
+package epub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// dtdEntityPattern matches a general internal entity declaration of a DTD
+// internal subset. Parameter entities (<!ENTITY % ...>) and external
+// entities (SYSTEM/PUBLIC) do not match.
+var dtdEntityPattern = regexp.MustCompile(`<!ENTITY\s+([A-Za-z_:][-A-Za-z0-9._:]*)\s+(?:"([^"]*)"|'([^']*)')\s*>`)
+
+// xmlRootStartPattern matches the start tag of an element.
+var xmlRootStartPattern = regexp.MustCompile(`<[A-Za-z_:]`)
+
+// Limits on DTD entity expansion, guarding against "billion laughs" style
+// documents.
+const (
+	maxEntityDepth     = 8
+	maxEntityValueSize = 64 << 10
+)
+
+// decodeXML unmarshals an XML document leniently: the data is transcoded to
+// UTF-8, a BOM is removed, entities declared in the DTD internal subset are
+// expanded and HTML named entities are replaced by numeric character
+// references before decoding.
+func decodeXML(data []byte, v any) error {
+	data, _, _ = toUTF8(data)
+	data = stripBOM(data)
+	data = expandDTDEntities(data)
+	data = preprocessHTMLEntities(data)
+	return xml.Unmarshal(data, v)
+}
+
+// expandDTDEntities replaces references to entities declared in the
+// document's DTD internal subset with their (escaped) replacement text.
+// References inside the DOCTYPE declaration itself are left alone.
+func expandDTDEntities(data []byte) []byte {
+	start, end := dtdInternalSubset(data)
+	if start < 0 {
+		return data
+	}
+
+	raw := make(map[string]string)
+	for _, m := range dtdEntityPattern.FindAllSubmatch(data[start:end], -1) {
+		name := string(m[1])
+		if xmlPredefinedEntities[name] {
+			continue
+		}
+		if _, ok := raw[name]; ok {
+			// The first declaration is binding.
+			continue
+		}
+		value := m[2]
+		if value == nil {
+			value = m[3]
+		}
+		raw[name] = string(value)
+	}
+	if len(raw) == 0 {
+		return data
+	}
+
+	entities := make(map[string][]byte, len(raw))
+	for name := range raw {
+		value, ok := expandEntityValue(raw, name, 0)
+		if !ok {
+			continue
+		}
+		var buf bytes.Buffer
+		_ = xml.EscapeText(&buf, []byte(html.UnescapeString(value)))
+		entities[name] = buf.Bytes()
+	}
+
+	// Bound the total output so that many references to large entities
+	// cannot exhaust memory.
+	budget := len(data) + maxEntityValueSize*16
+	body := xmlEntityRefPattern.ReplaceAllFunc(data[end:], func(match []byte) []byte {
+		value, ok := entities[string(match[1:len(match)-1])]
+		if !ok || budget < len(value) {
+			return match
+		}
+		budget -= len(value)
+		return value
+	})
+	return append(data[:end:end], body...)
+}
+
+// expandEntityValue returns the replacement text of the entity name with
+// nested references to other declared entities expanded. It fails for
+// recursive, too deeply nested or oversized values.
+func expandEntityValue(entities map[string]string, name string, depth int) (string, bool) {
+	if depth > maxEntityDepth {
+		return "", false
+	}
+	value := entities[name]
+	var sb strings.Builder
+	for {
+		loc := xmlEntityRefPattern.FindStringSubmatchIndex(value)
+		if loc == nil {
+			sb.WriteString(value)
+			break
+		}
+		sb.WriteString(value[:loc[0]])
+		ref := value[loc[2]:loc[3]]
+		if _, ok := entities[ref]; ok {
+			nested, ok := expandEntityValue(entities, ref, depth+1)
+			if !ok {
+				return "", false
+			}
+			sb.WriteString(nested)
+		} else {
+			sb.WriteString(value[loc[0]:loc[1]])
+		}
+		if sb.Len() > maxEntityValueSize {
+			return "", false
+		}
+		value = value[loc[1]:]
+	}
+	if sb.Len() > maxEntityValueSize {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// dtdInternalSubset locates the internal subset of the document's DOCTYPE
+// declaration. It returns the offset of the subset's opening '[' and the
+// offset just past the end of the DOCTYPE declaration, or -1, -1 when there
+// is no internal subset.
+func dtdInternalSubset(data []byte) (start, end int) {
+	i := bytes.Index(data, []byte("<!DOCTYPE"))
+	if i < 0 {
+		return -1, -1
+	}
+	// The DOCTYPE must precede the root element.
+	if root := xmlRootStartPattern.FindIndex(data); root != nil && root[0] < i {
+		return -1, -1
+	}
+
+	var quote byte
+	depth := 0
+	start = -1
+	for k := i + len("<!DOCTYPE"); k < len(data); k++ {
+		c := data[k]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			if start < 0 {
+				start = k
+			}
+			depth++
+		case c == ']':
+			depth--
+		case c == '>' && depth <= 0:
+			if start < 0 {
+				return -1, -1
+			}
+			return start, k + 1
+		}
+	}
+	return -1, -1
+}
@@ -0,0 +1,107 @@
+package epub
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeXML_Entities(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE doc [
+  <!ENTITY publisher "Acme &amp; Sons">
+  <!ENTITY full '&publisher; &mdash; &year;'>
+  <!ENTITY year "2024">
+  <!ENTITY % param "ignored">
+]>
+<doc><text>&full; &alpha;&rarr;&euro; &lt;ok&gt;</text></doc>`
+
+	var doc struct {
+		Text string `xml:"text"`
+	}
+	if err := decodeXML([]byte(data), &doc); err != nil {
+		t.Fatalf("decodeXML() error = %v", err)
+	}
+	if want := "Acme & Sons — 2024 α→€ <ok>"; doc.Text != want {
+		t.Errorf("Text = %q, want %q", doc.Text, want)
+	}
+}
+
+func TestExpandDTDEntities_Limits(t *testing.T) {
+	x := strings.Repeat("x", 100)
+	tests := []struct {
+		name string
+		dtd  string
+		want string
+	}{
+		{
+			name: "recursive",
+			dtd:  `<!ENTITY a "&b;"><!ENTITY b "&a;">`,
+			want: "<doc>&a;&d;</doc>",
+		},
+		{
+			name: "billion laughs",
+			dtd: `<!ENTITY a "` + x + `">` +
+				`<!ENTITY b "&a;&a;&a;&a;&a;&a;&a;&a;&a;&a;">` +
+				`<!ENTITY c "&b;&b;&b;&b;&b;&b;&b;&b;&b;&b;">` +
+				`<!ENTITY d "&c;&c;&c;&c;&c;&c;&c;&c;&c;&c;">`,
+			want: "<doc>" + x + "&d;</doc>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doctype := `<!DOCTYPE doc [` + tt.dtd + `]>`
+			got := expandDTDEntities([]byte(doctype + `<doc>&a;&d;</doc>`))
+			if want := doctype + tt.want; string(got) != want {
+				t.Errorf("expandDTDEntities() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestExpandDTDEntities_NoInternalSubset(t *testing.T) {
+	for _, data := range []string{
+		`<doc>&a;</doc>`,
+		`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1//EN" "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd"><html>&a;</html>`,
+		`<doc><!-- <!DOCTYPE x [<!ENTITY a "b">]> -->&a;</doc>`,
+	} {
+		if got := expandDTDEntities([]byte(data)); string(got) != data {
+			t.Errorf("expandDTDEntities(%q) = %q, want unchanged", data, got)
+		}
+	}
+}
+
+func TestParseOPF_HTML5Entities(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE package [<!ENTITY series "The &Aring;land Saga">]>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:uuid:entities</dc:identifier>
+    <dc:title>&alpha; to &Omega; &rarr; &series;</dc:title>
+    <dc:rights>&euro;5 &copy; 2024</dc:rights>
+  </metadata>
+  <manifest><item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/></manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`
+
+	pkg, err := parseOPF([]byte(data))
+	if err != nil {
+		t.Fatalf("parseOPF() error = %v", err)
+	}
+	if got, want := pkg.Metadata.Titles[0].Value, "α to Ω → The Åland Saga"; got != want {
+		t.Errorf("title = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeNCX_HTML5Entities(t *testing.T) {
+	data := `<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1"><navMap>
+<navPoint id="n1" playOrder="1"><navLabel><text>K&ouml;nig &amp; &Scaron;ime &larr;</text></navLabel><content src="ch1.xhtml"/></navPoint>
+</navMap></ncx>`
+
+	doc, err := decodeNCX([]byte(data))
+	if err != nil {
+		t.Fatalf("decodeNCX() error = %v", err)
+	}
+	if got, want := doc.NavMap.NavPoints[0].Label.Text, "König & Šime ←"; got != want {
+		t.Errorf("label = %q, want %q", got, want)
+	}
+}